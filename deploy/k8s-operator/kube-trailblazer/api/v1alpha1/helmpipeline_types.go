@@ -0,0 +1,233 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/nvidia/kube-trailblazer/pkg/helmer"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HelmPipelineSpec defines the desired state of HelmPipeline
+type HelmPipelineSpec struct {
+	// ManagementState indicates whether the operator is actively managing
+	// this pipeline's resources. Set to "Unmanaged" to have the reconciler
+	// skip it entirely.
+	// +kubebuilder:validation:Optional
+	ManagementState operatorv1.ManagementState `json:"managementState,omitempty"`
+
+	// Pipeline is the ordered list of Helm charts to reconcile.
+	Pipeline helmer.Pipeline `json:"pipeline"`
+
+	// RollbackOnFailure enables atomic pipeline semantics: if any stage of
+	// the pipeline fails to install/upgrade, previously-succeeded stages in
+	// this reconcile are reverted in reverse order: a release with a prior
+	// revision is rolled back to it through Helm, one without is
+	// uninstalled, and a stage with SkipRollback set is left as it is.
+	// +kubebuilder:validation:Optional
+	RollbackOnFailure bool `json:"rollbackOnFailure,omitempty"`
+
+	// Hooks run user-supplied manifests or Jobs between pipeline stages, at
+	// the lifecycle events they declare.
+	// +kubebuilder:validation:Optional
+	Hooks []helmer.PipelineHook `json:"hooks,omitempty"`
+
+	// MaxParallel bounds how many independent pipeline stages (per the DAG
+	// built from each stage's Needs) are installed/upgraded at once. Falls
+	// back to a small built-in default when zero.
+	// +kubebuilder:validation:Optional
+	MaxParallel int `json:"maxParallel,omitempty"`
+
+	// ContinueOnError keeps the DAG executor from skipping a stage's
+	// dependents just because that stage failed or was itself skipped,
+	// letting the rest of the pipeline proceed as far as it can instead of
+	// stopping at the first failure.
+	// +kubebuilder:validation:Optional
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+}
+
+// PipelineSignatureAnnotation holds a base64-encoded cosign signature over
+// this HelmPipeline's Spec.Pipeline (marshaled as canonical JSON), letting
+// HelmPipelineReconciler refuse to reconcile an unsigned or tampered
+// pipeline when its StrictSignatureVerification is enabled.
+const PipelineSignatureAnnotation = "package.nvidia.com/pipeline-signature"
+
+// DeletionFinalizer gates removal of a HelmPipeline until
+// HelmPipelineReconciler has run ReconcileDelete against its pipeline, so
+// cleanup runs through a real Kubernetes finalizer rather than relying on a
+// predicate-side snapshot alone.
+const DeletionFinalizer = "app.trailblazer.nvidia.com/finalizer"
+
+// Condition types reported on a HelmPipeline's status.
+const (
+	// ConditionTypeReady is True once every stage of the pipeline has been
+	// installed/upgraded and passed its readiness check.
+	ConditionTypeReady = "Ready"
+	// ConditionTypeProgressing is True while the reconciler is actively
+	// installing, upgrading, or waiting for readiness.
+	ConditionTypeProgressing = "Progressing"
+	// ConditionTypeDegraded is True when the last reconcile attempt failed.
+	ConditionTypeDegraded = "Degraded"
+	// ConditionTypeRollbackInProgress is True while a failed pipeline is
+	// being rolled back to its previous revision, and stays True afterwards
+	// when any release could not be rolled back (see RollbackOutcomes).
+	ConditionTypeRollbackInProgress = "RollbackInProgress"
+)
+
+// ReleaseStatus mirrors the observed state of a single pipeline stage's Helm
+// release.
+type ReleaseStatus struct {
+	// Name is the Helm release name.
+	Name string `json:"name"`
+	// Namespace is the namespace the release is deployed into.
+	Namespace string `json:"namespace"`
+	// Revision is the release revision currently deployed.
+	Revision int `json:"revision"`
+	// ChartVersion is the version of the chart that was deployed.
+	// +kubebuilder:validation:Optional
+	ChartVersion string `json:"chartVersion,omitempty"`
+	// Phase is the Helm release status (e.g. "deployed", "failed").
+	Phase string `json:"phase"`
+	// LastDeployed is when this revision was last deployed.
+	// +kubebuilder:validation:Optional
+	LastDeployed metav1.Time `json:"lastDeployed,omitempty"`
+	// FirstSeen is when this release was first observed by the reconciler.
+	// +kubebuilder:validation:Optional
+	FirstSeen metav1.Time `json:"firstSeen,omitempty"`
+	// LastTransitionTime is when Phase last changed.
+	// +kubebuilder:validation:Optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// StageStatus mirrors the DAG executor's (pkg/helmer's runStages) outcome
+// for one pipeline stage, keyed by its release name. It is reported only:
+// the reconciler does not read it back, so a retried reconcile runs every
+// stage again, already-succeeded ones included.
+type StageStatus struct {
+	// Name is the stage's ChartSpec.ReleaseName.
+	Name string `json:"name"`
+	// State is the DAG executor's outcome for this stage on the last reconcile.
+	State helmer.StageState `json:"state"`
+	// Message explains State, e.g. the error that failed this stage or the
+	// dependency that caused it to be skipped.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// RollbackOutcome mirrors helmer.ReconcileRollback's result for one release,
+// reporting what happened when a partial pipeline failure rolled back an
+// earlier stage's release.
+type RollbackOutcome struct {
+	// Name is the Helm release name.
+	Name string `json:"name"`
+	// Action is what ReconcileRollback did (or tried to do) to this release.
+	Action helmer.RollbackAction `json:"action"`
+	// Message explains a Failed Action, i.e. the error the uninstall/rollback
+	// call itself returned.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// DriftedResource reports one managed object whose live cluster state no
+// longer matches the release manifest the controllers.DriftDetector
+// rendered from this stage's current chart/values.
+type DriftedResource struct {
+	// ReleaseName is the stage's Helm release name, i.e. the pipeline stage
+	// the drifted object belongs to.
+	ReleaseName string `json:"releaseName"`
+	// APIVersion and Kind identify the drifted object's type.
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace and Name identify the drifted object.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Policy is the stage's HelmPackage.DriftPolicy in effect when drift was
+	// detected.
+	Policy helmer.DriftPolicy `json:"policy"`
+	// Message summarizes the detected difference.
+	Message string `json:"message"`
+	// LastDetected is when this drift was last observed.
+	LastDetected metav1.Time `json:"lastDetected"`
+}
+
+// HelmPipelineStatus defines the observed state of HelmPipeline
+type HelmPipelineStatus struct {
+	// ObservedGeneration is the most recent generation observed by the
+	// controller.
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions track the reconciler's view of the pipeline's progress and
+	// health. See the ConditionType* constants for the recognized types.
+	// +kubebuilder:validation:Optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// Releases mirrors the last-observed state of each pipeline stage.
+	// +kubebuilder:validation:Optional
+	Releases []ReleaseStatus `json:"releases,omitempty"`
+
+	// Stages mirrors the DAG executor's last-observed state for each
+	// pipeline stage. The reconciler does not read it back: every reconcile
+	// runs all stages again.
+	// +kubebuilder:validation:Optional
+	Stages []StageStatus `json:"stages,omitempty"`
+
+	// RollbackOutcomes reports, from the most recent rollback triggered by
+	// RollbackOnFailure, which releases were uninstalled/rolled back vs.
+	// left in a broken state.
+	// +kubebuilder:validation:Optional
+	RollbackOutcomes []RollbackOutcome `json:"rollbackOutcomes,omitempty"`
+
+	// DriftedResources mirrors controllers.DriftDetector's last sweep: every
+	// managed object whose live cluster state no longer matches this
+	// pipeline's rendered manifests.
+	// +kubebuilder:validation:Optional
+	DriftedResources []DriftedResource `json:"driftedResources,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// HelmPipeline is the Schema for the helmpipelines API
+type HelmPipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HelmPipelineSpec   `json:"spec,omitempty"`
+	Status HelmPipelineStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HelmPipelineList contains a list of HelmPipeline
+type HelmPipelineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HelmPipeline `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HelmPipeline{}, &HelmPipelineList{})
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/nvidia/kube-trailblazer/api/v1alpha1"
+	"github.com/nvidia/kube-trailblazer/pkg/helmer"
+)
+
+// defaultDriftInterval is how often DriftDetector sweeps every managed
+// HelmPipeline when Interval is unset.
+const defaultDriftInterval = 5 * time.Minute
+
+// DriftDetector periodically re-templates every managed HelmPipeline's
+// stages with their current values and diffs the rendered manifests against
+// the live cluster state, closing the gap between Helm's release-only view
+// and GitOps-style continuous reconciliation. It implements
+// sigs.k8s.io/controller-runtime/pkg/manager.Runnable so it can be added to
+// the same manager as HelmPipelineReconciler.
+type DriftDetector struct {
+	client.Client
+	RestConf *rest.Config
+	Recorder record.EventRecorder
+	// Interval is how often the detector sweeps every HelmPipeline.
+	// +optional
+	Interval time.Duration
+}
+
+// Start runs the drift sweep every Interval until ctx is canceled.
+func (d *DriftDetector) Start(ctx context.Context) error {
+	interval := d.Interval
+	if interval <= 0 {
+		interval = defaultDriftInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := d.sweep(ctx); err != nil {
+				klog.Error(err, "[DriftDetector]\tsweep failed")
+			}
+		}
+	}
+}
+
+// sweep lists every HelmPipeline and checks each of its stages whose
+// DriftPolicy is not Ignore for drift.
+func (d *DriftDetector) sweep(ctx context.Context) error {
+	var pipelines v1alpha1.HelmPipelineList
+	if err := d.List(ctx, &pipelines); err != nil {
+		return errors.Wrap(err, "[sweep]\tcannot list HelmPipelines")
+	}
+
+	for i := range pipelines.Items {
+		tb := &pipelines.Items[i]
+		if tb.Spec.ManagementState == operatorv1.Unmanaged {
+			continue
+		}
+		if err := d.sweepPipeline(ctx, tb); err != nil {
+			klog.Errorf("[sweep]\tdrift check failed for HelmPipeline %s:%s: %v", tb.GetNamespace(), tb.GetName(), err)
+		}
+	}
+	return nil
+}
+
+func (d *DriftDetector) sweepPipeline(ctx context.Context, tb *v1alpha1.HelmPipeline) error {
+	var drifted []v1alpha1.DriftedResource
+
+	for _, pkg := range tb.Spec.Pipeline {
+		if pkg.DriftPolicy == "" || pkg.DriftPolicy == helmer.DriftPolicyIgnore {
+			continue
+		}
+
+		found, err := d.detectStageDrift(ctx, tb, pkg)
+		if err != nil {
+			klog.Errorf("[sweepPipeline]\tcannot check release %s for drift: %v", pkg.ChartSpec.ReleaseName, err)
+			continue
+		}
+		drifted = append(drifted, found...)
+
+		if pkg.DriftPolicy == helmer.DriftPolicyReapply && len(found) > 0 {
+			if err := d.reapply(pkg); err != nil {
+				klog.Errorf("[sweepPipeline]\tcannot reapply drifted release %s: %v", pkg.ChartSpec.ReleaseName, err)
+			}
+		}
+	}
+
+	if len(drifted) == 0 && len(tb.Status.DriftedResources) == 0 {
+		return nil
+	}
+
+	for _, resource := range drifted {
+		d.Recorder.Eventf(tb, v1.EventTypeWarning, "DriftDetected", "%s %s/%s (release %s): %s", resource.Kind, resource.Namespace, resource.Name, resource.ReleaseName, resource.Message)
+	}
+
+	base := tb.DeepCopy()
+	tb.Status.DriftedResources = drifted
+	return d.Status().Patch(ctx, tb, client.MergeFrom(base))
+}
+
+// detectStageDrift renders pkg's chart with its current values and diffs
+// each rendered object's spec against the live cluster object.
+func (d *DriftDetector) detectStageDrift(ctx context.Context, tb *v1alpha1.HelmPipeline, pkg helmer.HelmPackage) ([]v1alpha1.DriftedResource, error) {
+	h, err := helmer.NewWithPackage(&pkg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[detectStageDrift]\tcannot create Helmer for release %s", pkg.ChartSpec.ReleaseName)
+	}
+	if err := h.GetClientsWithRestConf(d.RestConf); err != nil {
+		return nil, errors.Wrapf(err, "[detectStageDrift]\tcannot get clients for release %s", pkg.ChartSpec.ReleaseName)
+	}
+
+	manifest, err := h.RenderManifests()
+	if err != nil {
+		return nil, errors.Wrapf(err, "[detectStageDrift]\tcannot render manifests for release %s", pkg.ChartSpec.ReleaseName)
+	}
+
+	desired, err := splitManifests(manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[detectStageDrift]\tcannot parse rendered manifests for release %s", pkg.ChartSpec.ReleaseName)
+	}
+
+	now := metav1.Now()
+	var drifted []v1alpha1.DriftedResource
+	for _, obj := range desired {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		key := client.ObjectKeyFromObject(&obj)
+
+		if err := d.Get(ctx, key, live); err != nil {
+			if apierrors.IsNotFound(err) {
+				drifted = append(drifted, v1alpha1.DriftedResource{
+					ReleaseName:  pkg.ChartSpec.ReleaseName,
+					APIVersion:   obj.GetAPIVersion(),
+					Kind:         obj.GetKind(),
+					Namespace:    obj.GetNamespace(),
+					Name:         obj.GetName(),
+					Policy:       pkg.DriftPolicy,
+					Message:      "object is missing from the cluster",
+					LastDetected: now,
+				})
+				continue
+			}
+			return nil, errors.Wrapf(err, "[detectStageDrift]\tcannot get live object %s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+		}
+
+		if !equality.Semantic.DeepEqual(obj.Object["spec"], live.Object["spec"]) {
+			drifted = append(drifted, v1alpha1.DriftedResource{
+				ReleaseName:  pkg.ChartSpec.ReleaseName,
+				APIVersion:   obj.GetAPIVersion(),
+				Kind:         obj.GetKind(),
+				Namespace:    obj.GetNamespace(),
+				Name:         obj.GetName(),
+				Policy:       pkg.DriftPolicy,
+				Message:      "live spec no longer matches the rendered manifest",
+				LastDetected: now,
+			})
+		}
+	}
+
+	return drifted, nil
+}
+
+// reapply re-installs/upgrades pkg to restore its rendered manifest over a
+// drifted live object.
+func (d *DriftDetector) reapply(pkg helmer.HelmPackage) error {
+	h, err := helmer.NewWithPackage(&pkg)
+	if err != nil {
+		return errors.Wrapf(err, "[reapply]\tcannot create Helmer for release %s", pkg.ChartSpec.ReleaseName)
+	}
+	if err := h.GetClientsWithRestConf(d.RestConf); err != nil {
+		return errors.Wrapf(err, "[reapply]\tcannot get clients for release %s", pkg.ChartSpec.ReleaseName)
+	}
+	if err := h.AddOrUpdateRepo(); err != nil {
+		return errors.Wrapf(err, "[reapply]\tcannot refresh repo for release %s", pkg.ChartSpec.ReleaseName)
+	}
+	if err := h.InstallOrUpgradePackage(); err != nil {
+		return errors.Wrapf(err, "[reapply]\tcannot reinstall release %s", pkg.ChartSpec.ReleaseName)
+	}
+	klog.Infof("[reapply]\treapplied release %s to correct drift", pkg.ChartSpec.ReleaseName)
+	return nil
+}
+
+// splitManifests decodes a multi-document YAML manifest (as rendered by
+// helmer.RenderManifests) into its individual objects, skipping empty
+// documents the way Helm's own manifest splitting does.
+func splitManifests(manifest []byte) ([]unstructured.Unstructured, error) {
+	dec := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+
+	var objects []unstructured.Unstructured
+	for {
+		obj := unstructured.Unstructured{}
+		if err := dec.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("cannot decode manifest document: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
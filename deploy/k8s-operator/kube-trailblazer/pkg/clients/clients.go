@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	buildv1 "github.com/openshift/api/build/v1"
 	configv1 "github.com/openshift/api/config/v1"
@@ -11,11 +12,18 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/metadata"
 	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/record"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,14 +50,51 @@ type ClientsInterface interface {
 	GetPodLogs(namespace, podName string, podLogOpts *v1.PodLogOptions) *restclient.Request
 	GetNamespace(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Namespace, error)
 	GetSecret(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.Secret, error)
+	GetConfigMap(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.ConfigMap, error)
 	ClusterVersionGet(ctx context.Context, opts metav1.GetOptions) (result *configv1.ClusterVersion, err error)
 	Invalidate()
 	ServerGroups() (*metav1.APIGroupList, error)
+	ServerVersion() (*version.Info, error)
 	StatusUpdate(ctx context.Context, obj client.Object) error
 	CreateOrUpdate(ctx context.Context, obj client.Object, fn controllerutil.MutateFn) (controllerutil.OperationResult, error)
 	HasResource(resource schema.GroupVersionResource) (bool, error)
 	GetNodesByLabels(ctx context.Context, matchingLabels map[string]string) (*v1.NodeList, error)
 	GetPlatform() (string, error)
+	// GetMetadataClient returns a metadata.Interface for metadata-only
+	// reads/watches of arbitrary GVKs, backed by the same restConfig this
+	// ClientsInterface was constructed from.
+	GetMetadataClient() (metadata.Interface, error)
+	// GetPartial fetches key's *metav1.PartialObjectMetadata for gvk,
+	// without hydrating its full spec/status.
+	GetPartial(ctx context.Context, gvk schema.GroupVersionKind, key client.ObjectKey) (*metav1.PartialObjectMetadata, error)
+	// ListPartial lists gvk as a *metav1.PartialObjectMetadataList, without
+	// hydrating each item's full spec/status.
+	ListPartial(ctx context.Context, gvk schema.GroupVersionKind, opts ...client.ListOption) (*metav1.PartialObjectMetadataList, error)
+	// Eventf records a Kubernetes Event of eventtype ("Normal" or "Warning")
+	// against obj, surfacing it to "kubectl describe" instead of only klog
+	// output. It is a no-op when this ClientsInterface was constructed with
+	// no EventRecorder.
+	Eventf(obj runtime.Object, eventtype, reason, messageFmt string, args ...interface{})
+	// AnnotatedEventf is Eventf with Event annotations attached, for callers
+	// that need to correlate the Event with external systems.
+	AnnotatedEventf(obj runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{})
+	// RecordFailure records a Warning Event on obj summarizing err, the
+	// common case a reconcile error handler reaches for instead of calling
+	// Eventf directly.
+	RecordFailure(obj runtime.Object, err error)
+	// EnsureFinalizer adds finalizer to obj and persists it if it was not
+	// already present, reporting whether it added one.
+	EnsureFinalizer(ctx context.Context, obj client.Object, finalizer string) (bool, error)
+	// RemoveFinalizer removes finalizer from obj and persists it if it was
+	// present.
+	RemoveFinalizer(ctx context.Context, obj client.Object, finalizer string) error
+	// Helm returns a HelmInterface driving the Helm SDK directly against
+	// this ClientsInterface's cluster.
+	Helm() HelmInterface
+	// RESTConfig returns the rest.Config this ClientsInterface was built
+	// from, so a downstream helper (e.g. the Helm subsystem) can build its
+	// own getter against the same cluster.
+	RESTConfig() *restclient.Config
 }
 
 type k8sClients struct {
@@ -59,9 +104,26 @@ type k8sClients struct {
 	eventRecorder   record.EventRecorder
 	cachedDiscovery discovery.CachedDiscoveryInterface
 	restConfig      *restclient.Config
+
+	// resourceCache amortizes HasResource's discovery lookups (schema.
+	// GroupVersionResource -> bool) across calls on this ClientsInterface,
+	// so a ClientsFactory-cached per-cluster instance doesn't re-query
+	// discovery on every HasResource/GetPlatform call.
+	resourceCache sync.Map
+	// platformOnce/platformValue/platformErr amortize GetPlatform's result,
+	// since it never changes for a cluster's lifetime.
+	platformOnce  sync.Once
+	platformValue string
+	platformErr   error
 }
 
-func NewKubeClientsFromRestConf(restConfig *restclient.Config) (ClientsInterface, error) {
+// NewKubeClientsFromRestConf builds a ClientsInterface directly from a rest
+// config, for callers outside a controller-runtime manager that have no
+// EventRecorder of their own to pass in. recorder is optional: pass none to
+// get one built from kubeClientSet's own Events("") sink via
+// eventRecorderOrDefault, or pass a single recorder (e.g. a manager's) to
+// reuse it instead.
+func NewKubeClientsFromRestConf(restConfig *restclient.Config, recorder ...record.EventRecorder) (ClientsInterface, error) {
 	kubeClientSet, err := getKubeClientSet(restConfig)
 	if err != nil {
 		panic(err)
@@ -80,16 +142,29 @@ func NewKubeClientsFromRestConf(restConfig *restclient.Config) (ClientsInterface
 		panic(err)
 	}
 
+	eventRecorder := eventRecorderOrDefault(recorder, kubeClientSet)
+
 	return &k8sClients{
 		runtimeClient:   runtimeClient,
 		clientset:       *kubeClientSet,
 		configV1Client:  *configClient,
-		eventRecorder:   nil,
+		eventRecorder:   eventRecorder,
 		cachedDiscovery: cachedDiscoveryClient,
 		restConfig:      restConfig,
 	}, nil
 }
 
+// eventRecorderOrDefault returns recorders[0] when non-nil, or else a
+// recorder built from kubeClientSet's own Events("") sink.
+func eventRecorderOrDefault(recorders []record.EventRecorder, kubeClientSet *kubernetes.Clientset) record.EventRecorder {
+	if len(recorders) > 0 && recorders[0] != nil {
+		return recorders[0]
+	}
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "kube-trailblazer"})
+}
+
 func NewClients(runtimeClient client.Client, restConfig *restclient.Config, eventRecorder record.EventRecorder) (ClientsInterface, error) {
 	kubeClientSet, err := getKubeClientSet(restConfig)
 	if err != nil {
@@ -157,6 +232,96 @@ func (k *k8sClients) ServerGroups() (*metav1.APIGroupList, error) {
 	return k.cachedDiscovery.ServerGroups()
 }
 
+func (k *k8sClients) ServerVersion() (*version.Info, error) {
+	return k.cachedDiscovery.ServerVersion()
+}
+
+func (k *k8sClients) GetConfigMap(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.ConfigMap, error) {
+	return k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, opts)
+}
+
+func (k *k8sClients) Eventf(obj runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if k.eventRecorder == nil {
+		return
+	}
+	k.eventRecorder.Eventf(obj, eventtype, reason, messageFmt, args...)
+}
+
+func (k *k8sClients) AnnotatedEventf(obj runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	if k.eventRecorder == nil {
+		return
+	}
+	k.eventRecorder.AnnotatedEventf(obj, annotations, eventtype, reason, messageFmt, args...)
+}
+
+func (k *k8sClients) RecordFailure(obj runtime.Object, err error) {
+	k.Eventf(obj, v1.EventTypeWarning, "Failed", "%v", err)
+}
+
+func (k *k8sClients) EnsureFinalizer(ctx context.Context, obj client.Object, finalizer string) (bool, error) {
+	if controllerutil.ContainsFinalizer(obj, finalizer) {
+		return false, nil
+	}
+	controllerutil.AddFinalizer(obj, finalizer)
+	return true, k.runtimeClient.Update(ctx, obj)
+}
+
+func (k *k8sClients) RemoveFinalizer(ctx context.Context, obj client.Object, finalizer string) error {
+	if !controllerutil.ContainsFinalizer(obj, finalizer) {
+		return nil
+	}
+	controllerutil.RemoveFinalizer(obj, finalizer)
+	return k.runtimeClient.Update(ctx, obj)
+}
+
+func (k *k8sClients) GetMetadataClient() (metadata.Interface, error) {
+	return metadata.NewForConfig(k.restConfig)
+}
+
+// restMappingFor resolves gvk to its RESTMapping (and so its plural
+// GroupVersionResource) via the cached discovery client, the same source
+// ServerGroups/HasResource use.
+func (k *k8sClients) restMappingFor(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(k.cachedDiscovery)
+	return mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+func (k *k8sClients) GetPartial(ctx context.Context, gvk schema.GroupVersionKind, key client.ObjectKey) (*metav1.PartialObjectMetadata, error) {
+	mapping, err := k.restMappingFor(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve REST mapping for %s: %w", gvk, err)
+	}
+
+	metadataClient, err := k.GetMetadataClient()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create metadata client: %w", err)
+	}
+
+	return metadataClient.Resource(mapping.Resource).Namespace(key.Namespace).Get(ctx, key.Name, metav1.GetOptions{})
+}
+
+func (k *k8sClients) ListPartial(ctx context.Context, gvk schema.GroupVersionKind, opts ...client.ListOption) (*metav1.PartialObjectMetadataList, error) {
+	mapping, err := k.restMappingFor(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve REST mapping for %s: %w", gvk, err)
+	}
+
+	metadataClient, err := k.GetMetadataClient()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create metadata client: %w", err)
+	}
+
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	metaListOpts := *listOpts.AsListOptions()
+
+	resourceClient := metadataClient.Resource(mapping.Resource)
+	if listOpts.Namespace != "" {
+		return resourceClient.Namespace(listOpts.Namespace).List(ctx, metaListOpts)
+	}
+	return resourceClient.List(ctx, metaListOpts)
+}
+
 func (k *k8sClients) StatusUpdate(ctx context.Context, obj client.Object) error {
 	return k.runtimeClient.Status().Update(ctx, obj)
 }
@@ -166,43 +331,50 @@ func (k *k8sClients) CreateOrUpdate(ctx context.Context, obj client.Object, fn c
 }
 
 func (k *k8sClients) HasResource(resource schema.GroupVersionResource) (bool, error) {
-	dclient, err := discovery.NewDiscoveryClientForConfig(k.restConfig)
-	if err != nil {
-		return false, fmt.Errorf("Cannot retrieve a DiscoveryClient: %w", err)
-	}
-	if dclient == nil {
-		return false, nil
+	if cached, ok := k.resourceCache.Load(resource); ok {
+		return cached.(bool), nil
 	}
 
-	resources, err := dclient.ServerResourcesForGroupVersion(resource.GroupVersion().String())
+	resources, err := k.cachedDiscovery.ServerResourcesForGroupVersion(resource.GroupVersion().String())
 	if apierrors.IsNotFound(err) {
 		// entire group is missing
+		k.resourceCache.Store(resource, false)
 		return false, nil
 	}
 	if err != nil {
 		return false, fmt.Errorf("Cannot query ServerResources: %w", err)
-	} else {
-		for _, serverResource := range resources.APIResources {
-			if serverResource.Name == resource.Resource {
-				//Found it
-				return true, nil
-			}
+	}
+
+	for _, serverResource := range resources.APIResources {
+		if serverResource.Name == resource.Resource {
+			//Found it
+			k.resourceCache.Store(resource, true)
+			return true, nil
 		}
 	}
 
+	k.resourceCache.Store(resource, false)
 	return false, nil
 }
 
 func (k *k8sClients) GetPlatform() (string, error) {
-	clusterIsOCP, err := k.HasResource(buildv1.SchemeGroupVersion.WithResource("buildconfigs"))
-	if err != nil {
-		return "", err
-	}
-	if clusterIsOCP {
-		return "OCP", nil
-	} else {
-		return "K8S", nil
-	}
+	k.platformOnce.Do(func() {
+		clusterIsOCP, err := k.HasResource(buildv1.SchemeGroupVersion.WithResource("buildconfigs"))
+		if err != nil {
+			k.platformErr = err
+			return
+		}
+		if clusterIsOCP {
+			k.platformValue = "OCP"
+		} else {
+			k.platformValue = "K8S"
+		}
+	})
+	return k.platformValue, k.platformErr
+}
+
+func (k *k8sClients) RESTConfig() *restclient.Config {
+	return k.restConfig
 }
 
 func (k *k8sClients) GetNodesByLabels(ctx context.Context, matchingLabels map[string]string) (*v1.NodeList, error) {
@@ -0,0 +1,105 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// defaultKubeconfigSecretKey is the Secret data key ForCluster reads the
+// kubeconfig blob from when ClientsFactory.SecretKey is unset.
+const defaultKubeconfigSecretKey = "kubeconfig"
+
+// defaultClientsTTL bounds how long ForCluster/ForKubeconfigSecret reuse a
+// cached ClientsInterface when ClientsFactory.TTL is unset.
+const defaultClientsTTL = 10 * time.Minute
+
+// ClientsFactory builds a ClientsInterface per target cluster from a
+// kubeconfig Secret, letting a single controller running with one
+// in-cluster rest.Config reconcile HelmPipeline CRs that fan out charts to
+// fleet-managed clusters. Built ClientsInterfaces are cached by Secret
+// reference and data key for TTL, so their amortized HasResource/
+// GetPlatform discovery results are reused across reconciles instead of
+// re-querying the target cluster every time.
+type ClientsFactory struct {
+	// Reader is used to Get the kubeconfig Secret itself, typically the
+	// in-cluster ClientsInterface the controller already has for reading
+	// HelmPipeline CRs.
+	Reader ClientsInterface
+	// Namespace is where ForCluster looks up the Secret named by the
+	// cluster name passed to it.
+	Namespace string
+	// SecretKey is the default Secret data key a kubeconfig blob is read
+	// from. Defaults to "kubeconfig".
+	SecretKey string
+	// TTL bounds how long a cached ClientsInterface is reused before
+	// ForCluster/ForKubeconfigSecret rebuilds it. Defaults to 10 minutes.
+	TTL time.Duration
+
+	cache sync.Map // "<namespace>/<name>#<key>" of the Secret -> *cachedClients
+}
+
+// cachedClients is a ClientsFactory cache entry.
+type cachedClients struct {
+	client    ClientsInterface
+	expiresAt time.Time
+}
+
+// ForCluster returns the ClientsInterface for the cluster named name,
+// reading its kubeconfig from the Secret "name" in f.Namespace.
+func (f *ClientsFactory) ForCluster(ctx context.Context, name string) (ClientsInterface, error) {
+	return f.ForKubeconfigSecret(ctx, types.NamespacedName{Namespace: f.Namespace, Name: name}, f.SecretKey)
+}
+
+// ForKubeconfigSecret returns the ClientsInterface built from the
+// kubeconfig blob stored under key in the Secret secretRef, using
+// f.SecretKey (or "kubeconfig" if that is also unset) when key is empty.
+func (f *ClientsFactory) ForKubeconfigSecret(ctx context.Context, secretRef types.NamespacedName, key string) (ClientsInterface, error) {
+	if key == "" {
+		key = f.SecretKey
+	}
+	if key == "" {
+		key = defaultKubeconfigSecretKey
+	}
+
+	cacheKey := secretRef.String() + "#" + key
+	if cached, ok := f.cache.Load(cacheKey); ok {
+		entry := cached.(*cachedClients)
+		if time.Now().Before(entry.expiresAt) {
+			return entry.client, nil
+		}
+		f.cache.Delete(cacheKey)
+	}
+
+	secret, err := f.Reader.GetSecret(ctx, secretRef.Namespace, secretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get kubeconfig Secret %s: %w", secretRef, err)
+	}
+	kubeconfig, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("Secret %s has no key %q", secretRef, key)
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build rest.Config from Secret %s key %q: %w", secretRef, key, err)
+	}
+
+	clientsInterface, err := NewKubeClientsFromRestConf(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build ClientsInterface for Secret %s: %w", secretRef, err)
+	}
+
+	ttl := f.TTL
+	if ttl <= 0 {
+		ttl = defaultClientsTTL
+	}
+	f.cache.Store(cacheKey, &cachedClients{client: clientsInterface, expiresAt: time.Now().Add(ttl)})
+
+	return clientsInterface, nil
+}
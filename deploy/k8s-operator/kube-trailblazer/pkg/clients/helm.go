@@ -0,0 +1,199 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+// ownedLabel has the value of filter.OwnedLabel. It is repeated here because
+// pkg/filter imports this package indirectly (through api/v1alpha1 and
+// pkg/helmer), so importing pkg/filter from here would be an import cycle.
+const ownedLabel = "app.trailblazer.nvidia.com/owned-by"
+
+// HelmInterface drives Helm installs/upgrades/uninstalls/rollbacks directly
+// through the Helm SDK's action package, independent of pkg/helmer.Helmer
+// (which wraps go-helm-client for pipeline-stage orchestration). It gives a
+// caller with no full HelmPackage spec a way to drive Helm against this
+// ClientsInterface's cluster.
+type HelmInterface interface {
+	Install(ctx context.Context, releaseName, namespace string, chrt *chart.Chart, values map[string]interface{}) (*release.Release, error)
+	Upgrade(ctx context.Context, releaseName, namespace string, chrt *chart.Chart, values map[string]interface{}) (*release.Release, error)
+	Uninstall(ctx context.Context, releaseName, namespace string) error
+	List(ctx context.Context, namespace, selector string) ([]*release.Release, error)
+	Status(ctx context.Context, releaseName, namespace string) (*release.Release, error)
+	Rollback(ctx context.Context, releaseName, namespace string, revision int) error
+}
+
+// helmClients is the HelmInterface implementation backing k8sClients.Helm.
+type helmClients struct {
+	restConfig *restclient.Config
+	clientset  kubernetes.Clientset
+}
+
+func (k *k8sClients) Helm() HelmInterface {
+	return &helmClients{restConfig: k.restConfig, clientset: k.clientset}
+}
+
+// restClientGetterFor builds a genericclioptions.RESTClientGetter directly
+// from restConfig, instead of genericclioptions.NewConfigFlags' default of
+// re-reading kubeconfig from disk, so action.Configuration.Init talks to
+// the same cluster this ClientsInterface was constructed against.
+//
+// ConfigFlags only has fields for file-path TLS material (CAFile/CertFile/
+// KeyFile), so a restConfig carrying in-memory CAData/CertData/KeyData
+// instead (the normal shape for a kubeconfig sourced from a Secret, e.g.
+// ClientsFactory.ForKubeconfigSecret, as opposed to one referencing local
+// files) would otherwise have that material silently dropped. WrapConfigFn
+// overlays it onto the rest.Config ConfigFlags builds, after the fact.
+func restClientGetterFor(restConfig *restclient.Config, namespace string) genericclioptions.RESTClientGetter {
+	flags := genericclioptions.NewConfigFlags(false)
+	flags.Namespace = &namespace
+	flags.BearerToken = &restConfig.BearerToken
+	flags.APIServer = &restConfig.Host
+	flags.CAFile = &restConfig.CAFile
+	flags.WrapConfigFn = func(c *restclient.Config) *restclient.Config {
+		c.CAData = restConfig.CAData
+		c.CertData = restConfig.CertData
+		c.KeyData = restConfig.KeyData
+		return c
+	}
+	return flags
+}
+
+// configurationFor builds a fresh action.Configuration for namespace,
+// storing releases as Secrets (Helm's default, and the one filter.OwnedLabel
+// drift-detection expects).
+func (h *helmClients) configurationFor(namespace string) (*action.Configuration, error) {
+	cfg := new(action.Configuration)
+	getter := restClientGetterFor(h.restConfig, namespace)
+	if err := cfg.Init(getter, namespace, "secret", klog.Infof); err != nil {
+		return nil, fmt.Errorf("cannot init Helm action.Configuration for namespace %s: %w", namespace, err)
+	}
+	return cfg, nil
+}
+
+func (h *helmClients) Install(ctx context.Context, releaseName, namespace string, chrt *chart.Chart, values map[string]interface{}) (*release.Release, error) {
+	cfg, err := h.configurationFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	install := action.NewInstall(cfg)
+	install.ReleaseName = releaseName
+	install.Namespace = namespace
+
+	rel, err := install.Run(chrt, values)
+	if err != nil {
+		return nil, fmt.Errorf("cannot install release %s/%s: %w", namespace, releaseName, err)
+	}
+	if err := h.labelReleaseSecrets(ctx, namespace, releaseName); err != nil {
+		return rel, err
+	}
+	return rel, nil
+}
+
+func (h *helmClients) Upgrade(ctx context.Context, releaseName, namespace string, chrt *chart.Chart, values map[string]interface{}) (*release.Release, error) {
+	cfg, err := h.configurationFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	upgrade := action.NewUpgrade(cfg)
+	upgrade.Namespace = namespace
+
+	rel, err := upgrade.Run(releaseName, chrt, values)
+	if err != nil {
+		return nil, fmt.Errorf("cannot upgrade release %s/%s: %w", namespace, releaseName, err)
+	}
+	if err := h.labelReleaseSecrets(ctx, namespace, releaseName); err != nil {
+		return rel, err
+	}
+	return rel, nil
+}
+
+func (h *helmClients) Uninstall(ctx context.Context, releaseName, namespace string) error {
+	cfg, err := h.configurationFor(namespace)
+	if err != nil {
+		return err
+	}
+	if _, err := action.NewUninstall(cfg).Run(releaseName); err != nil {
+		return fmt.Errorf("cannot uninstall release %s/%s: %w", namespace, releaseName, err)
+	}
+	return nil
+}
+
+func (h *helmClients) List(ctx context.Context, namespace, selector string) ([]*release.Release, error) {
+	cfg, err := h.configurationFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	list := action.NewList(cfg)
+	list.Selector = selector
+
+	releases, err := list.Run()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list releases in namespace %s: %w", namespace, err)
+	}
+	return releases, nil
+}
+
+func (h *helmClients) Status(ctx context.Context, releaseName, namespace string) (*release.Release, error) {
+	cfg, err := h.configurationFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := action.NewStatus(cfg).Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get status for release %s/%s: %w", namespace, releaseName, err)
+	}
+	return rel, nil
+}
+
+func (h *helmClients) Rollback(ctx context.Context, releaseName, namespace string, revision int) error {
+	cfg, err := h.configurationFor(namespace)
+	if err != nil {
+		return err
+	}
+	rollback := action.NewRollback(cfg)
+	rollback.Version = revision
+
+	if err := rollback.Run(releaseName); err != nil {
+		return fmt.Errorf("cannot roll back release %s/%s to revision %d: %w", namespace, releaseName, revision, err)
+	}
+	return nil
+}
+
+// labelReleaseSecrets stamps filter.OwnedLabel onto the Secrets backing
+// releaseName, the same label the filter package's ownership checks look
+// for on any other owned resource, so drift in a release installed/
+// upgraded through HelmInterface is picked up by the existing predicates.
+func (h *helmClients) labelReleaseSecrets(ctx context.Context, namespace, releaseName string) error {
+	secrets, err := h.clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("name=%s,owner=helm", releaseName),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot list release Secrets for %s/%s: %w", namespace, releaseName, err)
+	}
+
+	for _, secret := range secrets.Items {
+		if _, ok := secret.Labels[ownedLabel]; ok {
+			continue
+		}
+		patched := secret.DeepCopy()
+		if patched.Labels == nil {
+			patched.Labels = map[string]string{}
+		}
+		patched.Labels[ownedLabel] = "true"
+		if _, err := h.clientset.CoreV1().Secrets(namespace).Update(ctx, patched, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("cannot label release Secret %s/%s: %w", namespace, secret.Name, err)
+		}
+	}
+	return nil
+}
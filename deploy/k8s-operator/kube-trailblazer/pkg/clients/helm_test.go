@@ -0,0 +1,29 @@
+package clients
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	restclient "k8s.io/client-go/rest"
+)
+
+var _ = Describe("restClientGetterFor", func() {
+	It("threads in-memory TLS material through to the built rest.Config", func() {
+		restConfig := &restclient.Config{
+			Host:        "https://cluster.example.com",
+			BearerToken: "a-token",
+			TLSClientConfig: restclient.TLSClientConfig{
+				CAData:   []byte("ca-data"),
+				CertData: []byte("cert-data"),
+				KeyData:  []byte("key-data"),
+			},
+		}
+
+		built, err := restClientGetterFor(restConfig, "default").ToRESTConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(built.Host).To(Equal(restConfig.Host))
+		Expect(built.BearerToken).To(Equal(restConfig.BearerToken))
+		Expect(built.CAData).To(Equal(restConfig.CAData))
+		Expect(built.CertData).To(Equal(restConfig.CertData))
+		Expect(built.KeyData).To(Equal(restConfig.KeyData))
+	})
+})
@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"sync"
+
+	"github.com/nvidia/kube-trailblazer/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeletionTracker snapshots a HelmPipeline's Spec at DELETE predicate time,
+// since by the time Reconcile runs the object is already gone and a Get
+// against the API server returns NotFound, and queues its key for the
+// reconciler to claim. It replaces the old package-global WorkStack, which
+// was an unsynchronized map mutated directly from predicate callbacks.
+type DeletionTracker struct {
+	queue workqueue.TypedRateLimitingInterface[client.ObjectKey]
+	// snapshots maps a tracked HelmPipeline's UID to its last-known spec.
+	snapshots sync.Map
+}
+
+// NewDeletionTracker returns an empty DeletionTracker, its queue backed by
+// client-go's default rate limiter.
+func NewDeletionTracker() *DeletionTracker {
+	return &DeletionTracker{
+		queue: workqueue.NewTypedRateLimitingQueue[client.ObjectKey](workqueue.DefaultTypedControllerRateLimiter[client.ObjectKey]()),
+	}
+}
+
+// Track snapshots tb's current spec and enqueues its key, called from
+// DeleteFunc while tb still exists.
+func (d *DeletionTracker) Track(tb *v1alpha1.HelmPipeline) {
+	d.snapshots.Store(tb.GetUID(), tb.DeepCopy())
+	d.queue.Add(client.ObjectKeyFromObject(tb))
+}
+
+// Claim looks up key's snapshotted HelmPipeline spec, removing it from the
+// tracker so it cannot be claimed twice.
+func (d *DeletionTracker) Claim(key client.ObjectKey) (*v1alpha1.HelmPipeline, bool) {
+	var found *v1alpha1.HelmPipeline
+	var uid types.UID
+
+	d.snapshots.Range(func(k, v interface{}) bool {
+		tb := v.(*v1alpha1.HelmPipeline)
+		if tb.GetNamespace() == key.Namespace && tb.GetName() == key.Name {
+			found, uid = tb, k.(types.UID)
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return nil, false
+	}
+	d.snapshots.Delete(uid)
+	return found, true
+}
+
+// Pop dequeues the next pending deletion and claims its snapshot, or
+// ok=false once nothing is pending. It is the non-blocking drain the
+// reconciler loops over, replacing the old
+// `filter.WorkStack["DELETE"].Pop()` call.
+func (d *DeletionTracker) Pop() (tb *v1alpha1.HelmPipeline, ok bool) {
+	if d.queue.Len() == 0 {
+		return nil, false
+	}
+	key, shutdown := d.queue.Get()
+	if shutdown {
+		return nil, false
+	}
+	defer d.queue.Done(key)
+	d.queue.Forget(key)
+
+	return d.Claim(key)
+}
@@ -3,7 +3,11 @@ package filter
 import (
 	"github.com/nvidia/kube-trailblazer/api/v1alpha1"
 	operatorv1 "github.com/openshift/api/operator/v1"
-	"golang.design/x/lockfree"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -15,27 +19,88 @@ const (
 	OwnedLabel = "app.trailblazer.nvidia.com/owned-by"
 )
 
-var (
-	WorkStack = make(map[string]*lockfree.Stack)
-)
+// helmPipelineGVK is compared against a *metav1.PartialObjectMetadata
+// event's stamped TypeMeta, since a metadata-only watch delivers no
+// concrete *v1alpha1.HelmPipeline Go type to assert against.
+var helmPipelineGVK = v1alpha1.GroupVersion.WithKind(Kind)
 
 type Filter interface {
 	GetPredicates() predicate.Predicate
 	GetMode() string
+	// Object returns the client.Object a builder's For/Owns call should
+	// watch for this Filter, or nil when the caller already has its own
+	// concretely-typed object to pass instead (the common case for
+	// NewFilter's default, typed watches).
+	Object() client.Object
 }
 
-func NewFilter() Filter {
-	WorkStack["DELETE"] = lockfree.NewStack()
+// NewFilter returns a Filter backed by tracker, which DeleteFunc snapshots
+// a deleted HelmPipeline's spec into for the reconciler to later Pop, and
+// recorder, which it emits Normal/Warning Events on the owning HelmPipeline
+// through as selectOnlyOwnedObjects dispatches Create/Update/Delete/Generic
+// transitions. Either may be nil: a nil tracker makes DELETE dispatch for
+// the HelmPipeline itself a no-op beyond the predicate's own true/false
+// (the caller then has no way to run ReconcileDelete against its spec), and
+// a nil recorder makes event emission a silent no-op.
+func NewFilter(tracker *DeletionTracker, recorder record.EventRecorder) Filter {
 	return &filter{
-		//log: log.WithName("filter"),
-		//lifecycle:  lifecycle,
-		//storage: storage,
-		//kernelData: kernelData,
+		tracker:  tracker,
+		recorder: recorder,
 	}
 }
 
+// NewMetadataFilter returns a Filter for a metadata-only watch of gvk, the
+// predicate-level equivalent of `Owns(&appsv1.Deployment{}, builder.
+// OnlyMetadata)` for an owned kind the caller has no concrete Go type for
+// (or simply wants to avoid hydrating full spec/status for). Its Object()
+// stamps gvk onto a *metav1.PartialObjectMetadata's TypeMeta, since
+// controller-runtime cannot otherwise infer the GVK of a bare
+// PartialObjectMetadata the way it does for a concretely-typed object
+// registered in the scheme.
+func NewMetadataFilter(gvk schema.GroupVersionKind) Filter {
+	return &filter{gvk: gvk}
+}
+
 type filter struct {
 	mode string
+	// gvk is set by NewMetadataFilter and stamped onto Object()'s TypeMeta.
+	// Zero for a NewFilter-created Filter, which has no metadata-only
+	// projection of its own.
+	gvk schema.GroupVersionKind
+	// recorder emits the Events selectOnlyOwnedObjects records against the
+	// owning HelmPipeline. Nil for a Filter built with no recorder, in
+	// which case event emission is a no-op.
+	recorder record.EventRecorder
+	// tracker receives the HelmPipeline's spec snapshot on DELETE, since by
+	// reconcile time the object is already gone. Nil for a Filter built
+	// with no tracker.
+	tracker *DeletionTracker
+}
+
+func (f *filter) Object() client.Object {
+	if f.gvk.Empty() {
+		return nil
+	}
+	return &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       f.gvk.Kind,
+			APIVersion: f.gvk.GroupVersion().String(),
+		},
+	}
+}
+
+// partialObjectGVK returns the GroupVersionKind stamped on obj's TypeMeta
+// when obj is a metadata-only projection (*metav1.PartialObjectMetadata or
+// *metav1.PartialObjectMetadataList, as delivered by an Owns(...,
+// builder.OnlyMetadata) watch), since such an object carries no concrete Go
+// type to type-assert against the way a fully-typed object does.
+func partialObjectGVK(obj runtime.Object) (schema.GroupVersionKind, bool) {
+	switch obj.(type) {
+	case *metav1.PartialObjectMetadata, *metav1.PartialObjectMetadataList:
+		return obj.GetObjectKind().GroupVersionKind(), true
+	default:
+		return schema.GroupVersionKind{}, false
+	}
 }
 
 func (f *filter) GetMode() string {
@@ -50,12 +115,27 @@ func (f *filter) isTrailblazerUnmanaged(obj client.Object) bool {
 	return tb.Spec.ManagementState == operatorv1.Unmanaged
 }
 
+// isHelmPipelineObject reports whether obj is the HelmPipeline CR itself,
+// recognizing both the fully-typed *v1alpha1.HelmPipeline (the common case,
+// since HelmPipeline is always watched via For, not OnlyMetadata) and a
+// *metav1.PartialObjectMetadata(List) whose stamped TypeMeta matches
+// helmPipelineGVK.
 func (f *filter) isHelmPipelineObject(obj client.Object) bool {
 
-	_, ok := obj.(*v1alpha1.HelmPipeline)
-	return ok
+	if _, ok := obj.(*v1alpha1.HelmPipeline); ok {
+		return true
+	}
+	if gvk, ok := partialObjectGVK(obj); ok {
+		return gvk == helmPipelineGVK
+	}
+	return false
 }
 
+// isOwned reports whether obj carries HelmPipeline's owner reference or
+// owned-by label. Both checks go through client.Object's generic
+// ObjectMeta accessors, so a *metav1.PartialObjectMetadata from a
+// metadata-only watch (whose OwnerReferences/Labels are populated the same
+// way a fully-typed object's are) is handled without any special-casing.
 func (f *filter) isOwned(obj client.Object) bool {
 
 	for _, owner := range obj.GetOwnerReferences() {
@@ -74,21 +154,74 @@ func (f *filter) isOwned(obj client.Object) bool {
 	return false
 }
 
+// eventf records a mode-tagged Event against target, a no-op when f has no
+// recorder (e.g. one built by NewMetadataFilter, or by NewFilter with a nil
+// recorder).
+func (f *filter) eventf(target runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if f.recorder == nil {
+		return
+	}
+	f.recorder.Eventf(target, eventtype, reason, messageFmt, args...)
+}
+
+// helmPipelineOwnerRef returns the HelmPipeline OwnerReference obj carries,
+// if any.
+func (f *filter) helmPipelineOwnerRef(obj client.Object) (metav1.OwnerReference, bool) {
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.Kind == Kind {
+			return owner, true
+		}
+	}
+	return metav1.OwnerReference{}, false
+}
+
+// owningHelmPipeline builds a reference to the HelmPipeline owning obj,
+// suitable for eventf: its TypeMeta is stamped explicitly so the recorder
+// doesn't need to resolve the GVK through the scheme the way it would for a
+// concretely-typed object, the same trick Object() uses for a metadata-only
+// watch's PartialObjectMetadata.
+func (f *filter) owningHelmPipeline(obj client.Object, owner metav1.OwnerReference) *v1alpha1.HelmPipeline {
+	hp := &v1alpha1.HelmPipeline{}
+	hp.TypeMeta = metav1.TypeMeta{Kind: Kind, APIVersion: v1alpha1.GroupVersion.String()}
+	hp.Namespace = obj.GetNamespace()
+	hp.Name = owner.Name
+	hp.UID = owner.UID
+	return hp
+}
+
 func (f *filter) selectOnlyOwnedObjects(obj client.Object) bool {
 
 	if f.isHelmPipelineObject(obj) {
 		if f.isTrailblazerUnmanaged(obj) {
+			f.eventf(obj, v1.EventTypeNormal, "ManagementStateUnmanaged", "%s skipped: Spec.ManagementState is Unmanaged", f.mode)
 			return false
 		}
 		klog.Infof("%s - isHelmPipeline - %s -- %s:%s", f.mode, obj.GetNamespace(), obj.GetObjectKind(), obj.GetName())
-		if f.mode == "DELETE" {
-			WorkStack[f.mode].Push(obj)
+		f.eventf(obj, v1.EventTypeNormal, "Reconciling", "%s %s:%s", f.mode, obj.GetNamespace(), obj.GetName())
+		if f.mode == "DELETE" && f.tracker != nil {
+			// A HelmPipeline carrying a DeletionTimestamp already went
+			// through the reconciler's finalizer path; only one deleted
+			// before it got DeletionFinalizer is tracked.
+			if tb, ok := obj.(*v1alpha1.HelmPipeline); ok && tb.GetDeletionTimestamp().IsZero() {
+				f.tracker.Track(tb)
+			}
 		}
 		return true
 	}
 
 	if f.isOwned(obj) {
 		klog.Infof("%s - isOwned - %s -- %s:%s", f.mode, obj.GetNamespace(), obj.GetObjectKind(), obj.GetName())
+		if owner, ok := f.helmPipelineOwnerRef(obj); ok {
+			eventtype := v1.EventTypeNormal
+			if f.mode == "DELETE" {
+				// An owned resource disappearing out from under the
+				// HelmPipeline is unexpected, unlike a CREATE/UPDATE/GENERIC
+				// notification of its own reconcile.
+				eventtype = v1.EventTypeWarning
+			}
+			f.eventf(f.owningHelmPipeline(obj, owner), eventtype, "OwnedResourceChanged",
+				"%s %s %s:%s", f.mode, obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName())
+		}
 		return true
 	}
 	return false
@@ -0,0 +1,186 @@
+package helmer
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/klog/v2"
+)
+
+// maxConcurrentStages bounds how many pipeline stages ReconcileCreate
+// installs at once when the caller passes no positive maxParallel; stages
+// that do not depend on each other are spread across this budget instead of
+// all starting at the same time.
+const maxConcurrentStages = 4
+
+// StageState is the DAG executor's outcome for one pipeline stage,
+// independent of whatever phase the stage's underlying Helm release itself
+// reports.
+type StageState string
+
+const (
+	StagePending   StageState = "Pending"
+	StageRunning   StageState = "Running"
+	StageSucceeded StageState = "Succeeded"
+	StageFailed    StageState = "Failed"
+	StageSkipped   StageState = "Skipped"
+)
+
+// StageStatus reports the DAG executor's outcome for a single pipeline
+// stage, keyed by its release name, so a caller can report it on the owning
+// HelmPipeline's status. ReconcileCreate takes no earlier StageStatuses, so
+// every call runs every stage.
+type StageStatus struct {
+	Name    string
+	State   StageState
+	Message string
+}
+
+// dagNode is one pipeline stage plus the edges (Needs, resolved to the nodes
+// they point at) the DAG executor walks before starting it.
+type dagNode struct {
+	pkg     HelmPackage
+	needs   []*dagNode
+	done    chan struct{}
+	state   StageState
+	err     error
+	release *release.Release
+}
+
+// buildDAG indexes pipeline by each stage's ChartSpec.ReleaseName and
+// resolves every HelmPackage.Needs entry into a direct edge, erroring on an
+// unknown dependency or a dependency cycle.
+func buildDAG(pipeline Pipeline) ([]*dagNode, error) {
+	nodes := make([]*dagNode, 0, len(pipeline))
+	byName := make(map[string]*dagNode, len(pipeline))
+
+	for _, pkg := range pipeline {
+		node := &dagNode{pkg: pkg, done: make(chan struct{}), state: StagePending}
+		nodes = append(nodes, node)
+		if pkg.ChartSpec.ReleaseName != "" {
+			byName[pkg.ChartSpec.ReleaseName] = node
+		}
+	}
+
+	for _, node := range nodes {
+		for _, need := range node.pkg.Needs {
+			dep, ok := byName[need]
+			if !ok {
+				return nil, errors.Errorf("[buildDAG]\tstage %s needs unknown release %q", node.pkg.ChartSpec.ReleaseName, need)
+			}
+			node.needs = append(node.needs, dep)
+		}
+	}
+
+	if err := detectCycle(nodes); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// detectCycle walks the Needs edges with the classic white/gray/black DFS
+// coloring, since a cyclic Needs graph would otherwise deadlock runStages.
+func detectCycle(nodes []*dagNode) error {
+	const (
+		white = iota
+		gray
+		black
+	)
+	color := make(map[*dagNode]int, len(nodes))
+
+	var visit func(n *dagNode) error
+	visit = func(n *dagNode) error {
+		switch color[n] {
+		case black:
+			return nil
+		case gray:
+			return errors.Errorf("[detectCycle]\tneeds cycle detected at stage %s", n.pkg.ChartSpec.ReleaseName)
+		}
+		color[n] = gray
+		for _, dep := range n.needs {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		color[n] = black
+		return nil
+	}
+
+	for _, n := range nodes {
+		if err := visit(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runStages runs every node of the DAG concurrently, bounded by maxParallel
+// (falling back to maxConcurrentStages when maxParallel is zero). Each node
+// waits for its Needs to finish before starting and is skipped outright once
+// any Need did not succeed, so a failure propagates to every downstream
+// stage instead of being retried against a dependency that will never be
+// ready, unless continueOnError is set, in which case a failed/skipped
+// dependency no longer skips its dependents. run is invoked at most once per
+// node.
+func runStages(nodes []*dagNode, maxParallel int, continueOnError bool, run func(HelmPackage) (*release.Release, error)) []StageStatus {
+	if maxParallel <= 0 {
+		maxParallel = maxConcurrentStages
+	}
+	sem := make(chan struct{}, maxParallel)
+	var wg sync.WaitGroup
+	wg.Add(len(nodes))
+
+	for _, node := range nodes {
+		go func(node *dagNode) {
+			defer wg.Done()
+			defer close(node.done)
+			// run executes third-party Helm/chart code in a detached
+			// goroutine with no caller to unwind into; a panic here would
+			// otherwise crash the whole controller-manager process and take
+			// every other pipeline's reconciliation down with it.
+			defer func() {
+				if r := recover(); r != nil {
+					node.state = StageFailed
+					node.err = errors.Errorf("[runStages]\tstage %s panicked: %v", node.pkg.ChartSpec.ReleaseName, r)
+					klog.Errorf("[runStages]\trecovered panic in stage %s: %v", node.pkg.ChartSpec.ReleaseName, r)
+				}
+			}()
+
+			for _, dep := range node.needs {
+				<-dep.done
+				if dep.state != StageSucceeded && !continueOnError {
+					node.state = StageSkipped
+					node.err = errors.Errorf("[runStages]\tskipped because dependency %s did not succeed", dep.pkg.ChartSpec.ReleaseName)
+					return
+				}
+			}
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			node.state = StageRunning
+			klog.Infof("[runStages]\tinstalling stage %s", node.pkg.ChartSpec.ReleaseName)
+			rel, err := run(node.pkg)
+			node.release = rel
+			if err != nil {
+				node.state, node.err = StageFailed, err
+				return
+			}
+			node.state = StageSucceeded
+		}(node)
+	}
+
+	wg.Wait()
+
+	statuses := make([]StageStatus, len(nodes))
+	for i, node := range nodes {
+		status := StageStatus{Name: node.pkg.ChartSpec.ReleaseName, State: node.state}
+		if node.err != nil {
+			status.Message = node.err.Error()
+		}
+		statuses[i] = status
+	}
+	return statuses
+}
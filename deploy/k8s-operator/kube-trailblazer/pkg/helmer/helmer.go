@@ -7,16 +7,21 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	helmclient "github.com/mittwald/go-helm-client"
 	"github.com/nvidia/kube-trailblazer/pkg/clients"
+	"github.com/nvidia/kube-trailblazer/pkg/statuscheck"
 	"github.com/nvidia/kube-trailblazer/pkg/utils"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -32,6 +37,17 @@ const (
 	FilterOwnedLabel = "app.trailblazer.nvidia.com/owned-by"
 )
 
+// defaultHookTimeout bounds how long ReconcileCreate/ReconcileDelete wait for
+// a PipelineHook's Job when the stage sets no ChartSpec.WaitTimeout.
+const defaultHookTimeout = 5 * time.Minute
+
+// repoMu serializes access to the Helm repository config/cache files that
+// GetClientsWithRestConf/GetClientsWithKubeConf point every Helmer at
+// ("/tmp/.helmrepo", "/tmp/.helmcache"). Since runStages now runs pipeline
+// stages concurrently, without this lock two stages' AddOrUpdateRepo calls
+// could read and write those shared files at the same time.
+var repoMu sync.Mutex
+
 func (h *Helmer) GetClientsWithRestConf(restConf *rest.Config) error {
 
 	var err error
@@ -155,8 +171,14 @@ func NewWithPackage(pkg *HelmPackage) (*Helmer, error) {
 	if h.Package.ChartSpec.ReleaseName == "" {
 		h.Package.ChartSpec.ReleaseName = h.Package.ChartSpec.ChartName + "-" + hash
 	}
-	// Replace the chart name with the full chart name
-	h.Package.ChartSpec.ChartName = h.Package.RepoEntry.Name + "/" + h.Package.ChartSpec.ChartName
+	// Replace the chart name with the full chart name. OCI registries have no
+	// repo name to resolve against, so the chart name is addressed directly
+	// as a fully-qualified oci:// reference instead.
+	if h.Package.RepoEntry.IsOCI() {
+		h.Package.ChartSpec.ChartName = strings.TrimSuffix(h.Package.RepoEntry.URL, "/") + "/" + h.Package.ChartSpec.ChartName
+	} else {
+		h.Package.ChartSpec.ChartName = h.Package.RepoEntry.Name + "/" + h.Package.ChartSpec.ChartName
+	}
 	// TODO: Is there a better place to have this logic? END
 
 	// This is needed for housekeeping between rootChart and childChart
@@ -225,25 +247,77 @@ func LoadPipelineFromObject(object map[string]interface{}) (Pipeline, error) {
 	return pipeline, nil
 }
 
-// GetChart loads the chart from the repo
-func (h *Helmer) GetChart(chartSpec *helmclient.ChartSpec) (*chart.Chart, error) {
+// GetChart loads the chart from the repo. ChartName may be a classic
+// "repoName/chart" reference or a fully-qualified "oci://..." reference;
+// go-helm-client resolves both the same way. The returned path is the local
+// chart archive go-helm-client downloaded it to, used by VerifyChart to
+// locate a classic repo chart's accompanying .prov file.
+func (h *Helmer) GetChart(chartSpec *helmclient.ChartSpec) (*chart.Chart, string, error) {
+
+	chart, path, err := h.Client.GetChart(chartSpec.ChartName, &action.ChartPathOptions{})
+	return chart, path, err
+
+}
+
+// preprocessValues resolves value references in the Package's ChartValues
+// and ValuesYaml and, when a KubeClient is available, templates ValuesYaml
+// against the live cluster facts, mutating h.Package in place. This is the
+// exact preprocessing InstallOrUpgradePackage needs before GetChart/install;
+// RenderManifests calls the same helper so a dry-run render sees the same
+// values an actual install would, instead of the raw, un-resolved Package.
+func (h *Helmer) preprocessValues() error {
+
+	if h.KubeClient != nil {
+		RegisterValueProvider("k8s", NewKubernetesSecretProvider(h.KubeClient))
+	}
+
+	resolvedValues, err := resolveValueRefs(h.Package.ChartValues)
+	if err != nil {
+		return errors.Wrapf(err, "\n[preprocessValues]\tcannot resolve value references for Package %s", h.Package.ChartSpec.ReleaseName)
+	}
+	h.Package.ChartValues = resolvedValues
+
+	if h.Package.ChartSpec.ValuesYaml != "" {
+		if h.KubeClient != nil {
+			facts, err := BuildClusterFacts(context.TODO(), h.KubeClient)
+			if err != nil {
+				return errors.Wrapf(err, "\n[preprocessValues]\tcannot build cluster facts for Package %s", h.Package.ChartSpec.ReleaseName)
+			}
+			templatedYaml, err := TemplateValuesYaml(context.TODO(), h.Package.ChartSpec.ValuesYaml, facts, h.KubeClient)
+			if err != nil {
+				return errors.Wrapf(err, "\n[preprocessValues]\tcannot template ValuesYaml for Package %s", h.Package.ChartSpec.ReleaseName)
+			}
+			h.Package.ChartSpec.ValuesYaml = templatedYaml
+		}
 
-	chart, _, err := h.Client.GetChart(chartSpec.ChartName, &action.ChartPathOptions{})
-	return chart, err
+		resolvedYaml, err := resolveValuesYaml(h.Package.ChartSpec.ValuesYaml)
+		if err != nil {
+			return errors.Wrapf(err, "\n[preprocessValues]\tcannot resolve value references in ValuesYaml for Package %s", h.Package.ChartSpec.ReleaseName)
+		}
+		h.Package.ChartSpec.ValuesYaml = resolvedYaml
+	}
 
+	return nil
 }
 
 // InstallOrUpgradePackage implements HelmHelper
 func (h *Helmer) InstallOrUpgradePackage() error {
 
+	if err := h.preprocessValues(); err != nil {
+		return err
+	}
+
 	// The graph chart values can override chart.Values
 	rootValues := h.Package.ChartValues
 
 	chartSpec := h.Package.ChartSpec.DeepCopy()
-	rootChart, err := h.GetChart(chartSpec)
+	rootChart, chartPath, err := h.GetChart(chartSpec)
 	if err != nil {
 		return errors.Wrapf(err, "\n[InstallOrUpgradePackage]\tcannot get Chart from Package %s", h.Package.ChartSpec.ReleaseName)
 	}
+	if err := h.VerifyChart(chartPath); err != nil {
+		return errors.Wrapf(err, "\n[InstallOrUpgradePackage]\tchart verification failed for Package %s", h.Package.ChartSpec.ReleaseName)
+	}
 	err = h.install(rootChart, &rootValues)
 	if err != nil {
 		return errors.Wrapf(err, "\n[InstallOrUpgradePackage]\tcannot install Chart from Package %s", h.Package.ChartSpec.ReleaseName)
@@ -356,6 +430,8 @@ func (h *Helmer) install(rootChart *chart.Chart, rootValues *chartutil.Values) e
 		return errors.Wrapf(err, "\n[Install]\tcannot setReleaseOwnerReference for charRelease %s", chartRelease.Name)
 	}
 
+	h.LastRelease = chartRelease
+
 	return nil
 }
 
@@ -455,9 +531,43 @@ func (h *Helmer) Template() error {
 	return nil
 }
 
-// AddOrUpdateRepo implements HelmHelper
+// RenderManifests templates this package's chart with its current values,
+// without installing it, returning the rendered manifests so a caller can
+// diff them against the live cluster state (controllers.DriftDetector). It
+// runs the chart through the same preprocessValues call and the same
+// PostRenderer (h.Run, Kustomize/patch/image-pinning steps included) that
+// InstallOrUpgradePackage's real install path does, so a stage using value
+// refs, cluster-fact templating, or PostRender steps renders the same
+// "desired" manifest it would actually install. Skipping either step here
+// would make the rendered manifest permanently diverge from the installed
+// release for any such stage, and drift detection would never stop firing.
+func (h *Helmer) RenderManifests() ([]byte, error) {
+	if err := h.preprocessValues(); err != nil {
+		return nil, errors.Wrap(err, "[RenderManifests]\tcannot preprocess values")
+	}
+
+	chartSpec := h.Package.ChartSpec.DeepCopy()
+	yamls, err := h.Client.TemplateChart(chartSpec, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "[RenderManifests]\ttemplating failed")
+	}
+
+	rendered, err := h.Run(bytes.NewBuffer(yamls))
+	if err != nil {
+		return nil, errors.Wrap(err, "[RenderManifests]\tpost-render failed")
+	}
+	return rendered.Bytes(), nil
+}
+
+// AddOrUpdateRepo implements HelmHelper. OCI registries have no chart index
+// to add/update, so an OCI RepoEntry instead logs in (when credentials are
+// configured), leaving the chart pull itself to GetChart/h.Client.GetChart.
 func (h *Helmer) AddOrUpdateRepo() error {
 
+	if h.Package.RepoEntry.IsOCI() {
+		return h.loginOCIRegistry()
+	}
+
 	var repoEntry repo.Entry
 
 	h.Package.RepoEntry.DeepCopyInto(&repoEntry)
@@ -468,11 +578,53 @@ func (h *Helmer) AddOrUpdateRepo() error {
 	return nil
 }
 
+// loginOCIRegistry authenticates against the OCI registry backing
+// h.Package.RepoEntry when Username/Password are set, writing credentials to
+// Helm's registry credential store so subsequent chart pulls (by this or any
+// other registry client, including the one go-helm-client created) are
+// authorized. With no Username set, it performs an anonymous pull: no login
+// is attempted and the registry must allow unauthenticated reads.
+//
+// Only Username/Password-authenticated and anonymous pulls are supported.
+// Plain-HTTP and private-CA OCI registries are out of scope: go-helm-client
+// constructs its own registry.Client internally for the actual chart pull
+// (GetChart) and exposes no hook to override its transport, so there is
+// nowhere to apply either here.
+func (h *Helmer) loginOCIRegistry() error {
+	host := strings.TrimPrefix(h.Package.RepoEntry.URL, ociScheme)
+	if idx := strings.Index(host, "/"); idx != -1 {
+		host = host[:idx]
+	}
+
+	if h.Package.RepoEntry.Username == "" {
+		klog.Infof("[loginOCIRegistry]\tno credentials configured for %s, pulling anonymously", host)
+		return nil
+	}
+
+	registryClient, err := registry.NewClient()
+	if err != nil {
+		return errors.Wrapf(err, "[loginOCIRegistry]\tcannot create registry client for %s", host)
+	}
+
+	loginOpts := []registry.LoginOption{
+		registry.LoginOptBasicAuth(h.Package.RepoEntry.Username, h.Package.RepoEntry.Password),
+		registry.LoginOptInsecure(h.Package.RepoEntry.InsecureSkipTLSverify),
+	}
+	if err := registryClient.Login(host, loginOpts...); err != nil {
+		return errors.Wrapf(err, "[loginOCIRegistry]\tcannot login to OCI registry %s", host)
+	}
+
+	return nil
+}
+
 func (h *Helmer) RunChartTests() (bool, error) {
 	return h.Client.RunChartTests(h.Package.ChartSpec.ReleaseName)
 }
 
-func ReconcileDelete(pipeline Pipeline, restConf *rest.Config) error {
+// ReconcileDelete uninstalls every stage of pipeline. If hookClient is
+// non-nil, pre-delete/post-delete PipelineHooks are run around each stage's
+// uninstall and a failed hook fails the reconcile for that stage.
+func ReconcileDelete(pipeline Pipeline, restConf *rest.Config, hookClient client.Client, hooks []PipelineHook) error {
 	for _, pkg := range UpdatePipelineWithDefaultChartSpec(pipeline) {
 
 		// For each chart we create an Helmer instance with its own settings
@@ -487,62 +639,155 @@ func ReconcileDelete(pipeline Pipeline, restConf *rest.Config) error {
 		if err != nil {
 			panic(err)
 		}
+
+		if hookClient != nil {
+			if err := RunHooks(context.TODO(), hookClient, pkg.ChartSpec.Namespace, hooks, HookPreDelete, hookTimeoutFor(pkg)); err != nil {
+				return errors.Wrapf(err, "[ReconcileDelete]\tpre-delete hooks failed for %s", pkg.ChartSpec.ReleaseName)
+			}
+		}
+
 		chartSpec := h.Package.ChartSpec.DeepCopy()
 		err = h.UninstallRelease(chartSpec)
 		if err != nil {
 			return errors.Wrapf(err, "\n[ReconcileDelete]\tcannot uninstall release %s", h.Package.ChartSpec.ReleaseName)
 		}
+
+		if hookClient != nil {
+			if err := RunHooks(context.TODO(), hookClient, pkg.ChartSpec.Namespace, hooks, HookPostDelete, hookTimeoutFor(pkg)); err != nil {
+				return errors.Wrapf(err, "[ReconcileDelete]\tpost-delete hooks failed for %s", pkg.ChartSpec.ReleaseName)
+			}
+		}
 	}
 	return nil
 }
 
-func ReconcileCreate(pipeline Pipeline, restConf *rest.Config) ([]*release.Release, error) {
+// hookTimeoutFor returns the stage's configured WaitTimeout, falling back to
+// defaultHookTimeout when unset.
+func hookTimeoutFor(pkg HelmPackage) time.Duration {
+	if pkg.ChartSpec.WaitTimeout > 0 {
+		return pkg.ChartSpec.WaitTimeout
+	}
+	return defaultHookTimeout
+}
 
-	var releases []*release.Release
+// ReconcileCreate installs or upgrades every stage of pipeline. Stages are
+// assembled into a DAG from each HelmPackage's Needs and run concurrently,
+// bounded by maxParallel (falling back to maxConcurrentStages when zero); a
+// stage is skipped once any stage it needs has failed or been skipped, so a
+// failure propagates to every downstream stage, unless continueOnError is
+// set. If hookClient is non-nil, pre-install/post-install (or pre-upgrade/
+// post-upgrade, for a release that already exists) PipelineHooks are run
+// around each stage, and the stage's WaitFor predicates (if any) are
+// evaluated before its dependents are unblocked. The returned StageStatuses
+// report each stage's outcome for the owning HelmPipeline's status;
+// ReconcileCreate takes no earlier statuses, so every call runs every stage.
+func ReconcileCreate(pipeline Pipeline, restConf *rest.Config, hookClient client.Client, hooks []PipelineHook, maxParallel int, continueOnError bool) ([]*release.Release, []StageStatus, error) {
+
+	nodes, err := buildDAG(UpdatePipelineWithDefaultChartSpec(pipeline))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "[ReconcileCreate]\tcannot build pipeline DAG")
+	}
 
-	for _, pkg := range UpdatePipelineWithDefaultChartSpec(pipeline) {
-		// For each chart we create an Helmer instance with its own settings
-		// this makes it easier to decouple each chart for processing and clients
-		// that do not interfere with each other.
-		h, err := NewWithPackage(&pkg)
-		if err != nil {
-			panic(err)
-		}
+	statuses := runStages(nodes, maxParallel, continueOnError, func(pkg HelmPackage) (*release.Release, error) {
+		return reconcileStage(pkg, restConf, hookClient, hooks)
+	})
 
-		err = h.GetClientsWithRestConf(restConf)
-		if err != nil {
-			panic(err)
+	var releases []*release.Release
+	var failed error
+	for _, node := range nodes {
+		if node.release != nil {
+			releases = append(releases, node.release)
 		}
-		err = h.AddOrUpdateRepo()
-		if err != nil {
-			return nil, err
+		if node.state == StageFailed && failed == nil {
+			failed = errors.Wrapf(node.err, "[ReconcileCreate]\tstage %s failed", node.pkg.ChartSpec.ReleaseName)
 		}
+	}
 
-		err = h.Lint()
-		if err != nil {
-			return nil, err
-		}
-		err = h.InstallOrUpgradePackage()
-		if err != nil {
-			return nil, err
-		}
-		ok, err := h.RunChartTests()
-		if !ok {
-			klog.Infof("[Reconcile]\tchart tests failed for %s", h.Package.ChartSpec.ReleaseName)
-			return nil, err
+	return releases, statuses, failed
+}
+
+// reconcileStage installs or upgrades a single pipeline stage, runs its
+// WaitFor predicates, and returns its resulting release so runStages can
+// unblock stages that declared it in their Needs.
+func reconcileStage(pkg HelmPackage, restConf *rest.Config, hookClient client.Client, hooks []PipelineHook) (*release.Release, error) {
+	// For each chart we create an Helmer instance with its own settings
+	// this makes it easier to decouple each chart for processing and clients
+	// that do not interfere with each other.
+	h, err := NewWithPackage(&pkg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[reconcileStage]\tcannot build Helmer for stage %s", pkg.ChartSpec.ReleaseName)
+	}
+
+	repoMu.Lock()
+	err = h.GetClientsWithRestConf(restConf)
+	if err != nil {
+		repoMu.Unlock()
+		return nil, errors.Wrapf(err, "[reconcileStage]\tcannot build clients for stage %s", pkg.ChartSpec.ReleaseName)
+	}
+	err = h.AddOrUpdateRepo()
+	repoMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.Lint()
+	if err != nil {
+		return nil, err
+	}
+
+	preEvent, postEvent := HookPreInstall, HookPostInstall
+	if _, existsErr := h.Client.GetRelease(pkg.ChartSpec.ReleaseName); existsErr == nil {
+		preEvent, postEvent = HookPreUpgrade, HookPostUpgrade
+	}
+
+	if hookClient != nil {
+		if err := RunHooks(context.TODO(), hookClient, pkg.ChartSpec.Namespace, hooks, preEvent, hookTimeoutFor(pkg)); err != nil {
+			return nil, errors.Wrapf(err, "[reconcileStage]\t%s hooks failed for %s", preEvent, pkg.ChartSpec.ReleaseName)
 		}
-		if err != nil {
-			klog.Infof("[Reconcile]\terror executing tests for %s", h.Package.ChartSpec.ReleaseName)
+	}
+
+	err = h.InstallOrUpgradePackage()
+	if err != nil {
+		return nil, err
+	}
 
+	if hookClient != nil {
+		if err := RunHooks(context.TODO(), hookClient, pkg.ChartSpec.Namespace, hooks, postEvent, hookTimeoutFor(pkg)); err != nil {
+			return nil, errors.Wrapf(err, "[reconcileStage]\t%s hooks failed for %s", postEvent, pkg.ChartSpec.ReleaseName)
 		}
-		if err == nil {
-			releases, err = h.ListDeployedReleases()
-			if err != nil {
-				return nil, err
-			}
+	}
+
+	ok, err := h.RunChartTests()
+	if !ok {
+		klog.Infof("[reconcileStage]\tchart tests failed for %s", h.Package.ChartSpec.ReleaseName)
+		return nil, err
+	}
+	if err != nil {
+		klog.Infof("[reconcileStage]\terror executing tests for %s", h.Package.ChartSpec.ReleaseName)
+	}
+
+	chartRelease, err := h.Client.GetRelease(pkg.ChartSpec.ReleaseName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[reconcileStage]\tcannot get release %s after install", pkg.ChartSpec.ReleaseName)
+	}
+
+	if len(pkg.WaitFor) == 0 {
+		return chartRelease, nil
+	}
+
+	if hookClient == nil {
+		klog.Warningf("[reconcileStage]\tno hookClient configured, skipping WaitFor predicates for %s", pkg.ChartSpec.ReleaseName)
+		return chartRelease, nil
+	}
+
+	deadline := time.Now().Add(hookTimeoutFor(pkg))
+	for _, predicate := range pkg.WaitFor {
+		if err := statuscheck.WaitForPredicate(context.TODO(), hookClient, chartRelease, predicate, deadline); err != nil {
+			return nil, errors.Wrapf(err, "[reconcileStage]\tWaitFor %q not satisfied for %s", predicate, pkg.ChartSpec.ReleaseName)
 		}
 	}
-	return releases, nil
+
+	return chartRelease, nil
 }
 
 // UpdateGrapshWithDefaultChartSpec updates a HelmPackage with default ChartSpec values
@@ -581,6 +826,110 @@ func UpdatePipelineWithDefaultChartSpec(in Pipeline) Pipeline {
 func (h *Helmer) UninstallRelease(spec *helmclient.ChartSpec) error {
 	return h.Client.UninstallRelease(spec)
 }
+
+// RollbackRelease implements HelmHelper. go-helm-client only supports
+// rolling back to the immediately preceding revision, so revision is
+// currently advisory and only used for logging/status reporting; callers
+// that need to roll further back should uninstall and reinstall the
+// recorded revision from storage instead.
+func (h *Helmer) RollbackRelease(spec *helmclient.ChartSpec, revision int) error {
+	klog.Infof("[RollbackRelease]\trolling back %s towards revision %d", spec.ReleaseName, revision)
+	return h.Client.RollbackRelease(spec)
+}
+
+// RollbackAction is what ReconcileRollback did (or tried to do) to one
+// release.
+type RollbackAction string
+
+const (
+	// RollbackActionUninstalled means the release had no revision prior to
+	// this reconcile's install, so it was removed entirely.
+	RollbackActionUninstalled RollbackAction = "Uninstalled"
+	// RollbackActionRolledBack means the release already existed before
+	// this reconcile's upgrade, so it was rolled back to its prior revision.
+	RollbackActionRolledBack RollbackAction = "RolledBack"
+	// RollbackActionSkipped means the stage's HelmPackage.SkipRollback was
+	// set, so the release was left as this reconcile installed/upgraded it.
+	RollbackActionSkipped RollbackAction = "Skipped"
+	// RollbackActionFailed means the uninstall/rollback call itself
+	// returned an error; the release was left in whatever state that call
+	// failed in.
+	RollbackActionFailed RollbackAction = "Failed"
+)
+
+// RollbackOutcome reports what ReconcileRollback did to a single release, so
+// a caller can tell operators which releases were reverted vs. left in a
+// broken state.
+type RollbackOutcome struct {
+	Name    string
+	Action  RollbackAction
+	Message string
+}
+
+// ReconcileRollback undoes the releases a failed ReconcileCreate call
+// actually got to: walking releases in reverse of the order stages were
+// declared (independent stages install concurrently, so this is not
+// necessarily the reverse of the order they were installed in), it uninstalls
+// a release that had no prior revision and rolls back one that did,
+// skipping any stage whose HelmPackage.SkipRollback is set. Unlike
+// ReconcileCreate it does not stop at the first failure, so one release
+// that can't be rolled back doesn't leave the rest of the pipeline
+// unreverted; every attempt's outcome is returned for the caller to persist
+// on status.
+func ReconcileRollback(pipeline Pipeline, restConf *rest.Config, releases []*release.Release) []RollbackOutcome {
+	packages := UpdatePipelineWithDefaultChartSpec(pipeline)
+	byName := make(map[string]HelmPackage, len(packages))
+	for _, pkg := range packages {
+		byName[pkg.ChartSpec.ReleaseName] = pkg
+	}
+
+	outcomes := make([]RollbackOutcome, 0, len(releases))
+	for i := len(releases) - 1; i >= 0; i-- {
+		rel := releases[i]
+
+		pkg, ok := byName[rel.Name]
+		if !ok {
+			continue
+		}
+		if pkg.SkipRollback {
+			klog.Infof("[ReconcileRollback]\tskipping %s, SkipRollback is set", rel.Name)
+			outcomes = append(outcomes, RollbackOutcome{Name: rel.Name, Action: RollbackActionSkipped})
+			continue
+		}
+
+		h, err := NewWithPackage(&pkg)
+		if err != nil {
+			outcomes = append(outcomes, RollbackOutcome{Name: rel.Name, Action: RollbackActionFailed, Message: err.Error()})
+			continue
+		}
+		if err := h.GetClientsWithRestConf(restConf); err != nil {
+			outcomes = append(outcomes, RollbackOutcome{Name: rel.Name, Action: RollbackActionFailed, Message: err.Error()})
+			continue
+		}
+
+		chartSpec := h.Package.ChartSpec.DeepCopy()
+		if rel.Version <= 1 {
+			if err := h.UninstallRelease(chartSpec); err != nil {
+				klog.Warningf("[ReconcileRollback]\tcould not uninstall %s: %v", rel.Name, err)
+				outcomes = append(outcomes, RollbackOutcome{Name: rel.Name, Action: RollbackActionFailed, Message: err.Error()})
+				continue
+			}
+			klog.Infof("[ReconcileRollback]\tuninstalled release %s, it had no prior revision", rel.Name)
+			outcomes = append(outcomes, RollbackOutcome{Name: rel.Name, Action: RollbackActionUninstalled})
+			continue
+		}
+
+		if err := h.RollbackRelease(chartSpec, rel.Version-1); err != nil {
+			klog.Warningf("[ReconcileRollback]\tcould not roll back %s: %v", rel.Name, err)
+			outcomes = append(outcomes, RollbackOutcome{Name: rel.Name, Action: RollbackActionFailed, Message: err.Error()})
+			continue
+		}
+		klog.Infof("[ReconcileRollback]\trolled back release %s", rel.Name)
+		outcomes = append(outcomes, RollbackOutcome{Name: rel.Name, Action: RollbackActionRolledBack})
+	}
+
+	return outcomes
+}
 func (h *Helmer) ListDeployedReleases() ([]*release.Release, error) {
 
 	ownedReleases := make([]*release.Release, 0)
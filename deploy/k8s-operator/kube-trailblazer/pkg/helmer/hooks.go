@@ -0,0 +1,244 @@
+package helmer
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const hookPollInterval = 2 * time.Second
+
+// PipelineHookEvent is a lifecycle point at which a PipelineHook can run,
+// mirroring Helm's own chart hook events (helm.sh/helm/v3/pkg/release.HookEvent).
+type PipelineHookEvent string
+
+const (
+	HookPreInstall  PipelineHookEvent = "pre-install"
+	HookPostInstall PipelineHookEvent = "post-install"
+	HookPreUpgrade  PipelineHookEvent = "pre-upgrade"
+	HookPostUpgrade PipelineHookEvent = "post-upgrade"
+	HookPreDelete   PipelineHookEvent = "pre-delete"
+	HookPostDelete  PipelineHookEvent = "post-delete"
+)
+
+// PipelineHookDeletePolicy governs when a hook's Job is cleaned up, mirroring
+// Helm's own hook-delete-policy annotation values.
+type PipelineHookDeletePolicy string
+
+const (
+	HookDeletePolicyBeforeHookCreation PipelineHookDeletePolicy = "before-hook-creation"
+	HookDeletePolicySucceeded          PipelineHookDeletePolicy = "hook-succeeded"
+	HookDeletePolicyFailed             PipelineHookDeletePolicy = "hook-failed"
+)
+
+// PipelineHook runs a user-supplied Job or raw manifest between pipeline
+// stages, independent of the hooks defined inside individual charts.
+type PipelineHook struct {
+	// Name uniquely identifies the hook and is used as the backing Job name.
+	Name string `json:"name"`
+	// Events are the lifecycle points at which this hook runs.
+	Events []PipelineHookEvent `json:"events"`
+	// Weight orders hooks within the same event, lowest first.
+	// +kubebuilder:validation:Optional
+	Weight int `json:"weight,omitempty"`
+	// DeletePolicy controls when the hook's Job is removed.
+	// +kubebuilder:validation:Optional
+	DeletePolicy PipelineHookDeletePolicy `json:"deletePolicy,omitempty"`
+	// JobSpec runs this hook as a Kubernetes Job.
+	// +optional
+	JobSpec *batchv1.JobSpec `json:"jobSpec,omitempty"`
+	// Manifest is applied as-is instead of JobSpec when set, for hooks that
+	// are not Jobs (e.g. a ConfigMap or a CRD that must exist first).
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Manifest runtime.RawExtension `json:"manifest,omitempty"`
+}
+
+func (in *PipelineHook) DeepCopyInto(out *PipelineHook) {
+	*out = *in
+	if in.Events != nil {
+		l := make([]PipelineHookEvent, len(in.Events))
+		copy(l, in.Events)
+		out.Events = l
+	}
+	if in.JobSpec != nil {
+		out.JobSpec = new(batchv1.JobSpec)
+		in.JobSpec.DeepCopyInto(out.JobSpec)
+	}
+	in.Manifest.DeepCopyInto(&out.Manifest)
+}
+
+func (in *PipelineHook) DeepCopy() *PipelineHook {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// RunHooks applies every PipelineHook declared for event, in ascending
+// weight order (lowest first), mirroring Helm's own chart hook weight
+// semantics. A hook backed by a JobSpec is waited on for completion before
+// the next hook for the same event runs; a failed Job fails the whole
+// reconcile for that phase.
+func RunHooks(ctx context.Context, c client.Client, namespace string, hooks []PipelineHook, event PipelineHookEvent, timeout time.Duration) error {
+	matching := make([]PipelineHook, 0, len(hooks))
+	for _, h := range hooks {
+		if hasEvent(h.Events, event) {
+			matching = append(matching, h)
+		}
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool { return matching[i].Weight < matching[j].Weight })
+
+	for _, hook := range matching {
+		if err := runHook(ctx, c, namespace, hook, timeout); err != nil {
+			return errors.Wrapf(err, "[RunHooks]\thook %s failed for event %s", hook.Name, event)
+		}
+	}
+	return nil
+}
+
+func hasEvent(events []PipelineHookEvent, event PipelineHookEvent) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func runHook(ctx context.Context, c client.Client, namespace string, hook PipelineHook, timeout time.Duration) error {
+	if hook.DeletePolicy == HookDeletePolicyBeforeHookCreation {
+		if err := deleteHookJob(ctx, c, namespace, hook.Name); err != nil {
+			return errors.Wrapf(err, "[runHook]\tcannot delete previous hook Job %s", hook.Name)
+		}
+	}
+
+	if hook.JobSpec == nil {
+		if len(hook.Manifest.Raw) == 0 {
+			klog.Infof("[runHook]\thook %s has neither JobSpec nor Manifest, nothing to do", hook.Name)
+			return nil
+		}
+		klog.Infof("[runHook]\thook %s has no JobSpec, applying raw manifest only", hook.Name)
+		return applyHookManifest(ctx, c, namespace, hook)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: hook.Name, Namespace: namespace},
+		Spec:       *hook.JobSpec.DeepCopy(),
+	}
+	if err := c.Create(ctx, job); err != nil {
+		return errors.Wrapf(err, "[runHook]\tcannot create hook Job %s", hook.Name)
+	}
+
+	succeeded, err := waitForHookJob(ctx, c, namespace, hook.Name, timeout)
+
+	switch {
+	case hook.DeletePolicy == HookDeletePolicySucceeded && succeeded:
+		if derr := deleteHookJob(ctx, c, namespace, hook.Name); derr != nil {
+			klog.Warningf("[runHook]\tcould not delete succeeded hook Job %s: %v", hook.Name, derr)
+		}
+	case hook.DeletePolicy == HookDeletePolicyFailed && !succeeded:
+		if derr := deleteHookJob(ctx, c, namespace, hook.Name); derr != nil {
+			klog.Warningf("[runHook]\tcould not delete failed hook Job %s: %v", hook.Name, derr)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+	if !succeeded {
+		return errors.Errorf("[runHook]\thook Job %s did not succeed", hook.Name)
+	}
+	return nil
+}
+
+// applyHookManifest decodes hook.Manifest and creates it, defaulting its
+// namespace to namespace when the manifest doesn't set its own, falling back
+// to a Get-then-overwrite Update when the object already exists. Unlike a
+// JobSpec hook, applying a manifest has no completion to wait on: the hook
+// is done as soon as the object is persisted.
+func applyHookManifest(ctx context.Context, c client.Client, namespace string, hook PipelineHook) error {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(hook.Manifest.Raw); err != nil {
+		return errors.Wrapf(err, "[applyHookManifest]\tcannot decode manifest for hook %s", hook.Name)
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(namespace)
+	}
+
+	err := c.Create(ctx, obj)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "[applyHookManifest]\tcannot create object for hook %s", hook.Name)
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		return errors.Wrapf(err, "[applyHookManifest]\tcannot get existing object for hook %s", hook.Name)
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	if err := c.Update(ctx, obj); err != nil {
+		return errors.Wrapf(err, "[applyHookManifest]\tcannot update existing object for hook %s", hook.Name)
+	}
+	return nil
+}
+
+// waitForHookJob polls the hook's Job until it reports Complete or Failed,
+// or timeout elapses.
+func waitForHookJob(ctx context.Context, c client.Client, namespace, name string, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	for {
+		job := &batchv1.Job{}
+		err := c.Get(ctx, key, job)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, errors.Wrapf(err, "[waitForHookJob]\tcannot get hook Job %s", name)
+		}
+
+		for _, cond := range job.Status.Conditions {
+			if cond.Status != v1.ConditionTrue {
+				continue
+			}
+			if cond.Type == batchv1.JobComplete {
+				return true, nil
+			}
+			if cond.Type == batchv1.JobFailed {
+				return false, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return false, errors.Errorf("[waitForHookJob]\ttimed out waiting for hook Job %s", name)
+		}
+		klog.Infof("[waitForHookJob]\twaiting for hook Job %s", name)
+		time.Sleep(hookPollInterval)
+	}
+}
+
+func deleteHookJob(ctx context.Context, c client.Client, namespace, name string) error {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	background := metav1.DeletePropagationBackground
+	err := c.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &background})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -16,8 +16,14 @@ type Interface interface {
 	AddOrUpdateRepo() error
 	GetClientsWithKubeConf(path string, kubeContext string) error
 	GetClientsWithRestConf(restConf *rest.Config) error
-	GetChart(char *helmclient.ChartSpec) (*chart.Chart, error)
+	// GetChart also returns the local path to the downloaded chart archive,
+	// so callers can locate an accompanying .prov file for VerifyChart.
+	GetChart(char *helmclient.ChartSpec) (*chart.Chart, string, error)
 	RunChartTests() (bool, error)
 	UninstallRelease(spec *helmclient.ChartSpec) error
 	ListDeployedReleases() ([]*release.Release, error)
+	// RollbackRelease rolls a release back to a prior revision. revision is
+	// advisory when the underlying Helm client only supports rolling back
+	// to the immediately preceding revision.
+	RollbackRelease(spec *helmclient.ChartSpec, revision int) error
 }
@@ -50,6 +50,17 @@ func (in *HelmPackage) DeepCopyInto(out *HelmPackage) {
 	out.RepoEntry = in.RepoEntry
 	out.ChartSpec = in.ChartSpec
 	out.ChartValues = in.ChartValues
+	if in.PostRender != nil {
+		out.PostRender = append([]PostRenderStep(nil), in.PostRender...)
+	}
+	out.CommonLabels = in.CommonLabels
+	out.CommonAnnotations = in.CommonAnnotations
+	if in.Needs != nil {
+		out.Needs = append([]string(nil), in.Needs...)
+	}
+	if in.WaitFor != nil {
+		out.WaitFor = append([]string(nil), in.WaitFor...)
+	}
 }
 
 func (in *HelmPackage) DeepCopy() *HelmPackage {
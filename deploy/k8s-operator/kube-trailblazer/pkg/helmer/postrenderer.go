@@ -2,67 +2,313 @@ package helmer
 
 import (
 	"bytes"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
 )
 
-func check(e error) {
-	if e != nil {
-		panic(e)
+// ownedByLabel is always injected by the Kustomize pass, even when a stage
+// configures no CommonLabels of its own, preserving the historical
+// ownership marker used to find releases this operator manages.
+const ownedByLabel = "app.trailblazer.nvidia.com/owned-by"
+
+// Run implements the Helm postrender.PostRenderer interface. It feeds
+// renderedManifests through h.Package.PostRender in declaration order. With
+// no PostRender steps configured, it falls back to the original
+// Kustomize-only behavior for backwards compatibility.
+func (h *Helmer) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
+
+	steps := h.Package.PostRender
+	if len(steps) == 0 {
+		return h.runKustomize(renderedManifests, "")
 	}
-}
 
-func mkdir(path string) error {
-	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(path, os.ModePerm)
+	manifests := renderedManifests
+	for _, step := range steps {
+		switch {
+		case step.Kustomize != "":
+			manifests, err = h.runKustomize(manifests, step.Kustomize)
+		case step.Template:
+			manifests, err = h.runTemplate(manifests)
+		case step.PinImages:
+			manifests, err = h.runPinImages(manifests)
+		case step.Patch != nil:
+			manifests, err = h.runPatch(manifests, *step.Patch)
+		default:
+			klog.Warningf("[Run]\tpost-render step %+v has no action set, skipping", step)
+		}
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("[Run]\tpost-render step %+v failed: %w", step, err)
 		}
 	}
-	return nil
+
+	return manifests, nil
 }
 
-func (h *Helmer) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
+// runKustomize adds the single file at overlay (when set) as an extra
+// resource alongside renderedManifests and applies the package's common
+// labels/annotations to the result; the file is not applied as an overlay
+// and a directory is not read. It builds the kustomization in-process against an
+// in-memory filesystem via sigs.k8s.io/kustomize/api/krusty, so no temp
+// directories or kustomize subprocess are needed.
+func (h *Helmer) runKustomize(renderedManifests *bytes.Buffer, overlay string) (*bytes.Buffer, error) {
 
-	kustomizePath := "/kustomize/"
-	chart := h.Package.ReleaseName + "-" + h.Package.ChartSpec.Version
-	basePath := kustomizePath + chart + "/base/"
+	const basePath = "/base"
+	fSys := filesys.MakeFsInMemory()
 
-	err = mkdir(basePath)
-	check(err)
+	manifests := bytes.Split(renderedManifests.Bytes(), []byte("\n---\n"))
 
 	var kustomization bytes.Buffer
-
-	manifests := bytes.Split(renderedManifests.Bytes(), []byte("---"))
-	if len(manifests[0]) == 0 {
-		manifests = manifests[1:]
-	}
-
 	kustomization.WriteString("resources:\n")
 	for i, manifest := range manifests {
-		// this cannot error per docs
+		if len(bytes.TrimSpace(manifest)) == 0 {
+			continue
+		}
 		name := fmt.Sprintf("resource-%d.yaml", i)
-		err := os.WriteFile(basePath+name, manifest, 0644)
-		check(err)
+		if err := fSys.WriteFile(filepath.Join(basePath, name), manifest); err != nil {
+			return nil, fmt.Errorf("[runKustomize]\tcannot write rendered manifest %s: %w", name, err)
+		}
 		fmt.Fprintf(&kustomization, " - %s\n", name)
 	}
 
-	kustomization.WriteString("\n")
-	kustomization.WriteString("commonLabels:\n")
-	kustomization.WriteString("  app.trailblazer.nvidia.com/owned-by: HelmOrchard\n")
+	if overlay != "" {
+		data, err := os.ReadFile(overlay)
+		if err != nil {
+			return nil, fmt.Errorf("[runKustomize]\tcannot read kustomize overlay %s: %w", overlay, err)
+		}
+		overlayName := filepath.Base(overlay)
+		if err := fSys.WriteFile(filepath.Join(basePath, overlayName), data); err != nil {
+			return nil, fmt.Errorf("[runKustomize]\tcannot stage kustomize overlay %s: %w", overlay, err)
+		}
+		fmt.Fprintf(&kustomization, " - %s\n", overlayName)
+	}
+
+	kustomization.WriteString(h.commonMetadataOverlay())
+
+	if err := fSys.WriteFile(filepath.Join(basePath, "kustomization.yaml"), kustomization.Bytes()); err != nil {
+		return nil, fmt.Errorf("[runKustomize]\tcannot write kustomization.yaml: %w", err)
+	}
+
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, basePath)
+	if err != nil {
+		return nil, fmt.Errorf("[runKustomize]\tkustomize build failed: %w", err)
+	}
+
+	out, err := m.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("[runKustomize]\tcannot render kustomize result: %w", err)
+	}
+
+	return bytes.NewBuffer(out), nil
+}
+
+// commonMetadataOverlay renders the commonLabels/commonAnnotations
+// kustomization block injected by every runKustomize pass, so
+// HelmPackage.CommonLabels/CommonAnnotations apply regardless of whether the
+// stage configures any other post-render steps, alongside the always-applied
+// ownedByLabel.
+func (h *Helmer) commonMetadataOverlay() string {
+	labels := map[string]string{ownedByLabel: "HelmOrchard"}
+	for k, v := range h.Package.CommonLabels {
+		labels[k] = v
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\ncommonLabels:\n")
+	for _, k := range sortedKeys(labels) {
+		fmt.Fprintf(&buf, "  %s: %q\n", k, labels[k])
+	}
+
+	if len(h.Package.CommonAnnotations) > 0 {
+		buf.WriteString("commonAnnotations:\n")
+		for _, k := range sortedKeys(h.Package.CommonAnnotations) {
+			fmt.Fprintf(&buf, "  %s: %q\n", k, h.Package.CommonAnnotations[k])
+		}
+	}
+
+	return buf.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runTemplate executes renderedManifests as a Go text/template (with sprig
+// functions) using the package's ChartValues as template data, so a
+// post-render step can do the kind of light templating Helm's own `tpl`
+// function does inside a chart.
+func (h *Helmer) runTemplate(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+
+	tmpl, err := template.New("postrender").Funcs(sprig.TxtFuncMap()).Parse(renderedManifests.String())
+	if err != nil {
+		return nil, fmt.Errorf("[runTemplate]\tcannot parse manifests as a template: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, h.Package.ChartValues); err != nil {
+		return nil, fmt.Errorf("[runTemplate]\tcannot execute manifest template: %w", err)
+	}
+
+	return &out, nil
+}
+
+// imageFieldRegex matches a YAML "image: <ref>" field, capturing the
+// indentation/key prefix and the (possibly quoted) image reference.
+var imageFieldRegex = regexp.MustCompile(`(?m)^(\s*image:\s*)["']?([^\s"'#]+)["']?\s*$`)
+
+// runPinImages rewrites every `image:` field in renderedManifests to address
+// its resolved digest instead of a mutable tag, via crane's default registry
+// client, which authenticates from the Docker config keychain rather than
+// the Helm registry config loginOCIRegistry writes to.
+func (h *Helmer) runPinImages(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+
+	out := imageFieldRegex.ReplaceAllFunc(renderedManifests.Bytes(), func(match []byte) []byte {
+		groups := imageFieldRegex.FindSubmatch(match)
+		prefix, ref := string(groups[1]), string(groups[2])
+
+		if strings.Contains(ref, "@sha256:") {
+			return match
+		}
+
+		digest, err := crane.Digest(ref)
+		if err != nil {
+			klog.Warningf("[runPinImages]\tcannot resolve digest for image %s, leaving it untouched: %v", ref, err)
+			return match
+		}
+
+		repo := ref
+		if idx := strings.LastIndex(ref, ":"); idx != -1 && !strings.Contains(ref[idx:], "/") {
+			repo = ref[:idx]
+		}
+
+		return []byte(prefix + repo + "@" + digest)
+	})
+
+	return bytes.NewBuffer(out), nil
+}
+
+// runPatch applies step's JSON Patch or JSON Merge Patch document to every
+// object in renderedManifests matching step.Target.
+func (h *Helmer) runPatch(renderedManifests *bytes.Buffer, step PatchStep) (*bytes.Buffer, error) {
+	objects, err := splitYAMLDocuments(renderedManifests.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("[runPatch]\tcannot parse rendered manifests: %w", err)
+	}
+
+	for i := range objects {
+		if !matchesPatchTarget(objects[i], step.Target) {
+			continue
+		}
+
+		raw, err := json.Marshal(objects[i].Object)
+		if err != nil {
+			return nil, fmt.Errorf("[runPatch]\tcannot marshal %s %s/%s: %w", objects[i].GetKind(), objects[i].GetNamespace(), objects[i].GetName(), err)
+		}
 
-	err = os.WriteFile(basePath+"kustomization.yaml", kustomization.Bytes(), 0644)
-	check(err)
+		var patched []byte
+		switch step.Type {
+		case PatchTypeMerge:
+			patched, err = jsonpatch.MergePatch(raw, step.Patch.Raw)
+		case PatchTypeJSON, "":
+			var patch jsonpatch.Patch
+			patch, err = jsonpatch.DecodePatch(step.Patch.Raw)
+			if err == nil {
+				patched, err = patch.Apply(raw)
+			}
+		default:
+			return nil, fmt.Errorf("[runPatch]\tunrecognized patch type %q", step.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("[runPatch]\tcannot apply patch to %s %s/%s: %w", objects[i].GetKind(), objects[i].GetNamespace(), objects[i].GetName(), err)
+		}
 
-	kustomize := exec.Command("kustomize", "build", basePath)
-	out, err := kustomize.Output()
-	check(err)
+		var out unstructured.Unstructured
+		if err := json.Unmarshal(patched, &out.Object); err != nil {
+			return nil, fmt.Errorf("[runPatch]\tcannot unmarshal patched object: %w", err)
+		}
+		objects[i] = out
+	}
+
+	return marshalYAMLDocuments(objects)
+}
 
-	// otherwise, print the output from running the command
-	//klog.Info("Output: ", string(out))
-	renderedManifests = bytes.NewBuffer(out)
+// matchesPatchTarget reports whether obj matches every non-empty field of
+// target, treating an empty field as a wildcard.
+func matchesPatchTarget(obj unstructured.Unstructured, target PatchTarget) bool {
+	gvk := obj.GroupVersionKind()
+	switch {
+	case target.Group != "" && target.Group != gvk.Group:
+		return false
+	case target.Version != "" && target.Version != gvk.Version:
+		return false
+	case target.Kind != "" && target.Kind != gvk.Kind:
+		return false
+	case target.Name != "" && target.Name != obj.GetName():
+		return false
+	case target.Namespace != "" && target.Namespace != obj.GetNamespace():
+		return false
+	default:
+		return true
+	}
+}
 
-	return renderedManifests, nil
+// splitYAMLDocuments decodes a multi-document YAML manifest into its
+// individual objects, skipping empty documents the way Helm's own manifest
+// splitting does.
+func splitYAMLDocuments(manifest []byte) ([]unstructured.Unstructured, error) {
+	dec := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+
+	var objects []unstructured.Unstructured
+	for {
+		obj := unstructured.Unstructured{}
+		if err := dec.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// marshalYAMLDocuments is splitYAMLDocuments' inverse, joining objects back
+// into a single "---"-separated manifest.
+func marshalYAMLDocuments(objects []unstructured.Unstructured) (*bytes.Buffer, error) {
+	var out bytes.Buffer
+	for i, obj := range objects {
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("[marshalYAMLDocuments]\tcannot marshal %s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		out.Write(data)
+	}
+	return &out, nil
 }
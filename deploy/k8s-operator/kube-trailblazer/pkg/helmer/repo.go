@@ -1,6 +1,22 @@
 package helmer
 
-import "helm.sh/helm/v3/pkg/repo"
+import (
+	"strings"
+
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// ociScheme is the URL scheme that marks a repoEntry as an OCI registry
+// rather than a classic HTTP(S) chart repository.
+const ociScheme = "oci://"
+
+// IsOCI reports whether in points at an OCI-compatible registry
+// (e.g. "oci://registry.example.com/charts") instead of a classic Helm chart
+// repository. OCI registries have no repo index to add/update, so callers
+// must branch on this before calling AddOrUpdateChartRepo.
+func (in *repoEntry) IsOCI() bool {
+	return strings.HasPrefix(in.URL, ociScheme)
+}
 
 func (in *repoEntry) DeepCopyInto(out *repo.Entry) *repo.Entry {
 	out.Name = in.Name
@@ -0,0 +1,153 @@
+package helmer
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/nvidia/kube-trailblazer/pkg/clients"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// gpuNodeLabel selects GPU-operator-labeled nodes when building ClusterFacts.
+var gpuNodeLabel = map[string]string{"nvidia.com/gpu.present": "true"}
+
+// clusterValueRefRegex matches "${secret:<namespace>/<name>/<key>}" and
+// "${configmap:<namespace>/<name>/<key>}" tokens embedded in ValuesYaml,
+// resolved directly against the live cluster, independent of the
+// "ref+<scheme>://..." ValueProvider mechanism in values.go.
+var clusterValueRefRegex = regexp.MustCompile(`\$\{(secret|configmap):([^/}]+)/([^/}]+)/([^}]+)\}`)
+
+// ClusterFacts summarizes the live cluster as template data for
+// TemplateValuesYaml, so a HelmPackage can template GPU-operator-style
+// values (e.g. the driver version needed per node group) without
+// pre-processing YAML outside the pipeline.
+type ClusterFacts struct {
+	// KubernetesVersion is the API server's reported version (e.g.
+	// "v1.28.4").
+	KubernetesVersion string
+	// NodeCount is the number of nodes in the cluster, not counting nodes
+	// with a NoSchedule or NoExecute taint.
+	NodeCount int
+	// GPUNodeCount is the number of nodes carrying gpuNodeLabel, not
+	// counting nodes with a NoSchedule or NoExecute taint.
+	GPUNodeCount int
+	// GPUNodeLabels is the label selector used to discover GPU nodes.
+	GPUNodeLabels map[string]string
+	// Platform is "OpenShift" or "Vanilla", per
+	// clients.ClientsInterface.GetPlatform.
+	Platform string
+}
+
+// BuildClusterFacts discovers ClusterFacts from the live cluster via
+// kubeClient.
+func BuildClusterFacts(ctx context.Context, kubeClient clients.ClientsInterface) (*ClusterFacts, error) {
+	version, err := kubeClient.ServerVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, "[BuildClusterFacts]\tcannot get server version")
+	}
+
+	allNodes, err := kubeClient.GetNodesByLabels(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "[BuildClusterFacts]\tcannot list nodes")
+	}
+
+	gpuNodes, err := kubeClient.GetNodesByLabels(ctx, gpuNodeLabel)
+	if err != nil {
+		return nil, errors.Wrap(err, "[BuildClusterFacts]\tcannot list GPU nodes")
+	}
+
+	platform, err := kubeClient.GetPlatform()
+	if err != nil {
+		return nil, errors.Wrap(err, "[BuildClusterFacts]\tcannot detect platform")
+	}
+
+	return &ClusterFacts{
+		KubernetesVersion: version.GitVersion,
+		NodeCount:         len(allNodes.Items),
+		GPUNodeCount:      len(gpuNodes.Items),
+		GPUNodeLabels:     gpuNodeLabel,
+		Platform:          platformName(platform),
+	}, nil
+}
+
+// platformName maps clients.ClientsInterface.GetPlatform's "OCP"/"K8S" to the
+// more descriptive names ClusterFacts templates read.
+func platformName(platform string) string {
+	if platform == "OCP" {
+		return "OpenShift"
+	}
+	return "Vanilla"
+}
+
+// TemplateValuesYaml expands valuesYaml as a Go text/template (with sprig
+// functions) using facts as its data, the same way runTemplate expands
+// rendered manifests in the post-render pipeline, then resolves any
+// "${secret:ns/name/key}" or "${configmap:ns/name/key}" token left in the
+// result against the live cluster via kubeClient. It runs before
+// resolveValuesYaml's "ref+<scheme>://..." resolution, so a "ref+" value
+// produced by the template still resolves normally afterwards.
+func TemplateValuesYaml(ctx context.Context, valuesYaml string, facts *ClusterFacts, kubeClient clients.ClientsInterface) (string, error) {
+	tmpl, err := template.New("values").Funcs(sprig.TxtFuncMap()).Parse(valuesYaml)
+	if err != nil {
+		return "", errors.Wrap(err, "[TemplateValuesYaml]\tcannot parse ValuesYaml as a template")
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, facts); err != nil {
+		return "", errors.Wrap(err, "[TemplateValuesYaml]\tcannot execute ValuesYaml template")
+	}
+
+	return resolveClusterValueRefs(ctx, out.String(), kubeClient)
+}
+
+// resolveClusterValueRefs replaces every "${secret:ns/name/key}" and
+// "${configmap:ns/name/key}" token in valuesYaml with the matching key's
+// value, fetched live from kubeClient. It resolves against the parsed YAML
+// tree rather than the raw text (see resolveRefsInYAML), since a Secret/
+// ConfigMap value is user-controlled and may contain a colon, quote, or
+// newline that would otherwise corrupt the surrounding document or inject
+// extra keys into it.
+func resolveClusterValueRefs(ctx context.Context, valuesYaml string, kubeClient clients.ClientsInterface) (string, error) {
+	return resolveRefsInYAML(valuesYaml, clusterValueRefRegex, func(ref string) (string, error) {
+		return resolveClusterValueRef(ctx, ref, kubeClient)
+	})
+}
+
+// resolveClusterValueRef resolves a single "${secret:...}"/"${configmap:...}"
+// token against kubeClient.
+func resolveClusterValueRef(ctx context.Context, ref string, kubeClient clients.ClientsInterface) (string, error) {
+	matches := clusterValueRefRegex.FindStringSubmatch(ref)
+	if matches == nil {
+		return "", errors.Errorf("[resolveClusterValueRef]\tmalformed reference %q", ref)
+	}
+	kind, namespace, name, key := matches[1], matches[2], matches[3], matches[4]
+
+	switch kind {
+	case "secret":
+		secret, err := kubeClient.GetSecret(ctx, namespace, name, metav1.GetOptions{})
+		if err != nil {
+			return "", errors.Wrapf(err, "[resolveClusterValueRef]\tcannot get Secret %s/%s", namespace, name)
+		}
+		value, ok := secret.Data[key]
+		if !ok {
+			return "", errors.Errorf("[resolveClusterValueRef]\tSecret %s/%s has no key %q", namespace, name, key)
+		}
+		return string(value), nil
+	case "configmap":
+		cm, err := kubeClient.GetConfigMap(ctx, namespace, name, metav1.GetOptions{})
+		if err != nil {
+			return "", errors.Wrapf(err, "[resolveClusterValueRef]\tcannot get ConfigMap %s/%s", namespace, name)
+		}
+		value, ok := cm.Data[key]
+		if !ok {
+			return "", errors.Errorf("[resolveClusterValueRef]\tConfigMap %s/%s has no key %q", namespace, name, key)
+		}
+		return value, nil
+	default:
+		return "", errors.Errorf("[resolveClusterValueRef]\tunknown reference kind %q", kind)
+	}
+}
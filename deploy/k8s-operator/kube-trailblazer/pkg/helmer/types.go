@@ -7,6 +7,8 @@ import (
 	"github.com/mittwald/go-helm-client/values"
 	"github.com/nvidia/kube-trailblazer/pkg/clients"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
 // Type Guard asserting that Helmer satisfies the Helmer interface.
@@ -19,14 +21,28 @@ type Helmer struct {
 	Options    helmclient.GenericHelmOptions `json:"helmOptions"`
 	KubeClient clients.ClientsInterface      `json:"kubeClient"`
 	Debug      bool                          `json:"debug"`
+	// LastRelease is the release installed/upgraded by the most recent
+	// InstallOrUpgradePackage call, so WaitReady can poll its rendered
+	// manifest without the caller having to thread it through separately.
+	LastRelease *release.Release `json:"-"`
 }
 
 // Entry represents a collection of parameters for chart repository, since
-// we cannot annotate the internal helm struct we're doing it here
+// we cannot annotate the internal helm struct we're doing it here.
+//
+// URL also accepts an OCI registry reference (e.g.
+// "oci://registry.example.com/charts"), in which case Name/CertFile/KeyFile/
+// CAFile/PassCredentialsAll are ignored: OCI registries have no chart index
+// to add/update, and charts are addressed directly by their oci:// location.
 type repoEntry struct {
 	// +kubebuilder:validation:Optional
 	Name string `json:"name"`
 	URL  string `json:"url"`
+	// Username, when set, is used to authenticate against URL. For an OCI
+	// registry this logs in via the Helm SDK's registry client instead of
+	// Helm's classic repo credentials. Leaving it unset performs an
+	// anonymous pull against an OCI registry that allows unauthenticated
+	// reads.
 	// +kubebuilder:validation:Optional
 	Username string `json:"username"`
 	// +kubebuilder:validation:Optional
@@ -41,6 +57,33 @@ type repoEntry struct {
 	InsecureSkipTLSverify bool `json:"insecure_skip_tls_verify"`
 	// +kubebuilder:validation:Optional
 	PassCredentialsAll bool `json:"pass_credentials_all"`
+	// Verify configures provenance/signature verification for charts pulled
+	// from this repo, used by any HelmPackage in this repo whose ChartSpec
+	// sets Verify. Required in that case.
+	// +kubebuilder:validation:Optional
+	Verify *VerifyConfig `json:"verify,omitempty"`
+}
+
+// VerifyConfig names the key material used to validate a chart's
+// authenticity before install: a classic repo chart's Helm provenance
+// (.prov) file against a PGP keyring, or an OCI chart's cosign signature
+// against a public key and Rekor transparency log.
+type VerifyConfig struct {
+	// Keyring is a path to a PGP keyring (as produced by `gpg --export`)
+	// used to validate a classic repo chart's accompanying .prov file, the
+	// same check `helm install --verify --keyring` performs. Required for
+	// non-OCI repos.
+	// +kubebuilder:validation:Optional
+	Keyring string `json:"keyring,omitempty"`
+	// CosignPublicKey is a path to a PEM-encoded cosign public key used to
+	// validate an OCI chart's cosign signature. Required for OCI repos.
+	// +kubebuilder:validation:Optional
+	CosignPublicKey string `json:"cosignPublicKey,omitempty"`
+	// RekorURL overrides the Rekor transparency log consulted for an OCI
+	// chart's signature inclusion proof. Defaults to the public Sigstore
+	// instance.
+	// +kubebuilder:validation:Optional
+	RekorURL string `json:"rekorURL,omitempty"`
 }
 type chartSpec struct {
 	// +kubebuilder:validation:Optional
@@ -54,6 +97,19 @@ type chartSpec struct {
 	// use string instead of map[string]interface{}
 	// https://github.com/kubernetes-sigs/kubebuilder/issues/528#issuecomment-466449483
 	// and https://github.com/kubernetes-sigs/controller-tools/pull/317
+	//
+	// Any "ref+<scheme>://..." token (vals-style, e.g.
+	// "ref+vault://secret/data/myapp#password") is resolved against the
+	// scheme's registered helmer.ValueProvider before install/upgrade, so a
+	// HelmPipeline CR can be kept in git without inlining credentials.
+	//
+	// Before that, ValuesYaml is expanded as a Go template (with sprig
+	// functions) against a helmer.ClusterFacts describing the live cluster
+	// (Kubernetes version, node/GPU node counts, OpenShift/Vanilla
+	// platform), and any "${secret:ns/name/key}" or
+	// "${configmap:ns/name/key}" token is replaced with that key's live
+	// value, letting a pipeline author template values (e.g. a GPU driver
+	// version per node group) without pre-processing YAML externally.
 	// +optional
 	ValuesYaml string `json:"valuesYaml,omitempty"`
 	// Specify values similar to the cli
@@ -130,6 +186,96 @@ type chartSpec struct {
 	// KeepHistory indicates whether to retain or purge the release history during uninstall
 	// +optional
 	KeepHistory bool `json:"keepHistory,omitempty"`
+	// WaitTimeout bounds this stage's pipeline hooks and WaitFor predicates.
+	// The reconciler's readiness check (pkg/statuscheck) runs once for the
+	// whole pipeline and uses the longest WaitTimeout declared across its
+	// stages. Each falls back to a default when zero.
+	// +optional
+	WaitTimeout time.Duration `json:"waitTimeout,omitempty"`
+	// Verify requires this release's chart to pass provenance/signature
+	// verification (per the owning HelmPackage's RepoEntry.Verify) before
+	// install/upgrade, refusing the release otherwise.
+	// +optional
+	Verify bool `json:"verify,omitempty"`
+	// VerifyProvenance requires this release's chart to pass its .prov
+	// signature check against RepoEntry.Verify.Keyring before install/
+	// upgrade, the same check Verify performs for a classic repo chart.
+	// Unlike Verify, it is rejected for an OCI repo, since oci:// charts
+	// have no .prov file to check.
+	// +optional
+	VerifyProvenance bool `json:"verifyProvenance,omitempty"`
+}
+
+// PostRenderStep is one stage of a HelmPackage's post-render pipeline,
+// applied in declaration order to the manifests Helm hands to the
+// PostRenderer before they reach the cluster. Exactly one of Kustomize,
+// Template, PinImages, or Patch is expected to be set per step.
+type PostRenderStep struct {
+	// Kustomize points at a single YAML file (relative to the operator's
+	// working directory) whose objects are added as an extra kustomization
+	// resource alongside the manifests rendered so far. The file is not
+	// applied as an overlay, and a directory is not read.
+	// +kubebuilder:validation:Optional
+	Kustomize string `json:"kustomize,omitempty"`
+	// Template runs the manifests rendered so far through a Go text/template
+	// (with sprig functions) using HelmPackage.ChartValues as the template
+	// data.
+	// +kubebuilder:validation:Optional
+	Template bool `json:"template,omitempty"`
+	// PinImages rewrites every `image:` field in the manifests rendered so
+	// far to its resolved digest (repo@sha256:...), via crane's default
+	// registry client. No registry client or credentials from the
+	// HelmPackage are used for the lookup.
+	// +kubebuilder:validation:Optional
+	PinImages bool `json:"pinImages,omitempty"`
+	// Patch applies a JSON Patch or JSON Merge Patch overlay to every
+	// rendered object matching its Target.
+	// +kubebuilder:validation:Optional
+	Patch *PatchStep `json:"patch,omitempty"`
+}
+
+// PatchType selects how a PatchStep.Patch document is applied to a matching
+// object.
+type PatchType string
+
+const (
+	// PatchTypeJSON applies Patch as an RFC 6902 JSON Patch (a JSON array of
+	// operations). This is the default when Type is empty.
+	PatchTypeJSON PatchType = "json"
+	// PatchTypeMerge applies Patch as an RFC 7396 JSON Merge Patch (a JSON
+	// object merged into the target).
+	PatchTypeMerge PatchType = "merge"
+)
+
+// PatchTarget selects which rendered objects a PatchStep applies to. A zero
+// field matches any value, so an empty PatchTarget matches every object.
+type PatchTarget struct {
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+	// +kubebuilder:validation:Optional
+	Kind string `json:"kind,omitempty"`
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// PatchStep is a PostRenderStep that overlays a JSON Patch or JSON Merge
+// Patch document onto every rendered object matching Target.
+type PatchStep struct {
+	// Target selects which rendered objects Patch applies to.
+	Target PatchTarget `json:"target"`
+	// Patch is the raw patch document: a JSON array of RFC 6902 operations
+	// when Type is PatchTypeJSON, or a JSON merge object when Type is
+	// PatchTypeMerge.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Patch apiextensionsv1.JSON `json:"patch"`
+	// Type selects how Patch is applied. Defaults to PatchTypeJSON.
+	// +kubebuilder:validation:Optional
+	Type PatchType `json:"type,omitempty"`
 }
 
 // A shelter of vines or branches or of latticework covered with climbing
@@ -142,9 +288,64 @@ type HelmPackage struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Type=object
 	// TODO ChartValues json.RawMessage `json:"chartValues"`
+	// Any string leaf of the form "ref+<scheme>://..." is resolved the same
+	// way as in ChartSpec.ValuesYaml before install/upgrade.
 	ChartValues chartutil.Values `json:"chartValues"`
 	// +kubebuilder:validation:Optional
 	ReleaseName string `json:"releaseName"`
+	// PostRender is the ordered chain of post-render steps applied to this
+	// stage's manifests. An empty chain keeps the previous, Kustomize-only
+	// behavior.
+	// +kubebuilder:validation:Optional
+	PostRender []PostRenderStep `json:"postRender,omitempty"`
+	// CommonLabels/CommonAnnotations are injected onto every object this
+	// stage renders, regardless of whether any PostRender steps are
+	// configured, on top of the always-applied
+	// app.trailblazer.nvidia.com/owned-by label.
+	// +kubebuilder:validation:Optional
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	// +kubebuilder:validation:Optional
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+	// Needs lists the ChartSpec.ReleaseName of other stages in this Pipeline
+	// that must finish installing/upgrading (and satisfy their own WaitFor,
+	// if any) before this stage starts. Stages that do not depend on each
+	// other, directly or through other stages, may run concurrently.
+	// +kubebuilder:validation:Optional
+	Needs []string `json:"needs,omitempty"`
+	// WaitFor lists extra readiness predicates evaluated against this
+	// stage's own release after install/upgrade, before its dependents are
+	// unblocked. Accepted forms: "CRD established", "Deployment ready",
+	// "Job <name> Succeeded". Backed by pkg/statuscheck's per-kind readiness
+	// checks.
+	// +kubebuilder:validation:Optional
+	WaitFor []string `json:"waitFor,omitempty"`
+	// SkipRollback excludes this stage from ReconcileRollback's automatic
+	// teardown when the owning HelmPipeline's RollbackOnFailure pipeline
+	// fails partway through, e.g. a stage whose release other HelmPipelines
+	// also depend on and that must not be uninstalled just because a later
+	// stage in this pipeline failed.
+	// +kubebuilder:validation:Optional
+	SkipRollback bool `json:"skipRollback,omitempty"`
+	// DriftPolicy controls what controllers.DriftDetector does when this
+	// stage's live cluster state no longer matches its rendered manifest.
+	// Defaults to DriftPolicyIgnore when empty.
+	// +kubebuilder:validation:Optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
 }
 
+// DriftPolicy governs how controllers.DriftDetector reacts to a stage's
+// live cluster state diverging from its rendered manifest.
+type DriftPolicy string
+
+const (
+	// DriftPolicyIgnore does not check this stage for drift.
+	DriftPolicyIgnore DriftPolicy = "Ignore"
+	// DriftPolicyWarn records drift on HelmPipeline.status.driftedResources
+	// and emits a Kubernetes Event, without touching the live object.
+	DriftPolicyWarn DriftPolicy = "Warn"
+	// DriftPolicyReapply does what DriftPolicyWarn does, and additionally
+	// re-installs/upgrades the stage to restore the rendered manifest.
+	DriftPolicyReapply DriftPolicy = "Reapply"
+)
+
 type Pipeline []HelmPackage
@@ -0,0 +1,184 @@
+package helmer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/nvidia/kube-trailblazer/pkg/clients"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+func init() {
+	if provider, err := NewVaultProvider(); err != nil {
+		klog.Warningf("[init]\tcannot register Vault ValueProvider: %v", err)
+	} else {
+		RegisterValueProvider("vault", provider)
+	}
+
+	if provider, err := NewAWSSecretsManagerProvider(context.Background()); err != nil {
+		klog.Warningf("[init]\tcannot register AWS Secrets Manager ValueProvider: %v", err)
+	} else {
+		RegisterValueProvider("awssm", provider)
+	}
+
+	RegisterValueProvider("file", FileProvider{})
+}
+
+// VaultProvider resolves "vault://<path>#<key>" references against a
+// HashiCorp Vault KV v2 secret engine, authenticating the same way the
+// Vault Go client does outside of a request (VAULT_ADDR/VAULT_TOKEN, or
+// whatever agent/auth method is configured in the environment).
+type VaultProvider struct {
+	client *vaultapi.Client
+}
+
+// NewVaultProvider builds a VaultProvider from Vault's own environment-based
+// client configuration.
+func NewVaultProvider() (*VaultProvider, error) {
+	client, err := vaultapi.NewClient(vaultapi.DefaultConfig())
+	if err != nil {
+		return nil, errors.Wrap(err, "[NewVaultProvider]\tcannot create Vault client")
+	}
+	return &VaultProvider{client: client}, nil
+}
+
+// Get reads path from Vault's KV v2 engine and returns the value stored
+// under key.
+func (p *VaultProvider) Get(uri string) (string, error) {
+	rest := strings.TrimPrefix(uri, "vault://")
+	path, key := splitKeyFragment(rest)
+	if key == "" {
+		return "", errors.Errorf("[VaultProvider.Get]\tmissing #<key> in %q", uri)
+	}
+
+	secret, err := p.client.Logical().Read(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "[VaultProvider.Get]\tcannot read Vault secret %s", path)
+	}
+	if secret == nil || secret.Data == nil {
+		return "", errors.Errorf("[VaultProvider.Get]\tVault secret %s not found", path)
+	}
+
+	// KV v2 nests the actual secret fields under a "data" key; fall back to
+	// the top level for a KV v1 mount.
+	data, _ := secret.Data["data"].(map[string]interface{})
+	if data == nil {
+		data = secret.Data
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", errors.Errorf("[VaultProvider.Get]\tVault secret %s has no key %q", path, key)
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+// AWSSecretsManagerProvider resolves "awssm://<secret-name>#<key>"
+// references against AWS Secrets Manager, using the default AWS SDK
+// credential chain.
+type AWSSecretsManagerProvider struct {
+	client *secretsmanager.Client
+}
+
+// NewAWSSecretsManagerProvider builds an AWSSecretsManagerProvider from the
+// ambient AWS configuration (env vars, shared config/credentials files, or
+// an attached IAM role).
+func NewAWSSecretsManagerProvider(ctx context.Context) (*AWSSecretsManagerProvider, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "[NewAWSSecretsManagerProvider]\tcannot load AWS config")
+	}
+	return &AWSSecretsManagerProvider{client: secretsmanager.NewFromConfig(cfg)}, nil
+}
+
+// Get fetches secret-name's current value. When the secret's string value is
+// a JSON object, key selects one field from it; with no #<key>, the whole
+// secret string is returned as-is.
+func (p *AWSSecretsManagerProvider) Get(uri string) (string, error) {
+	rest := strings.TrimPrefix(uri, "awssm://")
+	name, key := splitKeyFragment(rest)
+
+	out, err := p.client.GetSecretValue(context.TODO(), &secretsmanager.GetSecretValueInput{SecretId: &name})
+	if err != nil {
+		return "", errors.Wrapf(err, "[AWSSecretsManagerProvider.Get]\tcannot get secret %s", name)
+	}
+	if out.SecretString == nil {
+		return "", errors.Errorf("[AWSSecretsManagerProvider.Get]\tsecret %s has no SecretString", name)
+	}
+	if key == "" {
+		return *out.SecretString, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(*out.SecretString), &fields); err != nil {
+		return "", errors.Wrapf(err, "[AWSSecretsManagerProvider.Get]\tsecret %s is not a JSON object, cannot select key %q", name, key)
+	}
+	value, ok := fields[key]
+	if !ok {
+		return "", errors.Errorf("[AWSSecretsManagerProvider.Get]\tsecret %s has no key %q", name, key)
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+// KubernetesSecretProvider resolves "k8s://<namespace>/<secret>#<key>"
+// references against in-cluster Kubernetes Secrets, via the operator's own
+// KubeClient.
+type KubernetesSecretProvider struct {
+	client clients.ClientsInterface
+}
+
+// NewKubernetesSecretProvider builds a KubernetesSecretProvider backed by
+// client, typically a Helmer's own h.KubeClient.
+func NewKubernetesSecretProvider(client clients.ClientsInterface) *KubernetesSecretProvider {
+	return &KubernetesSecretProvider{client: client}
+}
+
+// Get reads namespace/secret's data entry for key.
+func (p *KubernetesSecretProvider) Get(uri string) (string, error) {
+	rest := strings.TrimPrefix(uri, "k8s://")
+	ref, key := splitKeyFragment(rest)
+	if key == "" {
+		return "", errors.Errorf("[KubernetesSecretProvider.Get]\tmissing #<key> in %q", uri)
+	}
+
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		return "", errors.Errorf("[KubernetesSecretProvider.Get]\texpected <namespace>/<secret> in %q", uri)
+	}
+
+	secret, err := p.client.GetSecret(context.TODO(), namespace, name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "[KubernetesSecretProvider.Get]\tcannot get Secret %s/%s", namespace, name)
+	}
+
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", errors.Errorf("[KubernetesSecretProvider.Get]\tSecret %s/%s has no key %q", namespace, name, key)
+	}
+	return string(value), nil
+}
+
+// FileProvider resolves "file://<path>" references by reading path from the
+// local filesystem, for secrets mounted into the operator's own Pod (e.g.
+// via a projected Secret volume).
+type FileProvider struct{}
+
+// Get reads path and returns its contents with surrounding whitespace
+// trimmed, so a trailing newline in the mounted file doesn't leak into the
+// resolved value.
+func (FileProvider) Get(uri string) (string, error) {
+	path := strings.TrimPrefix(uri, "file://")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "[FileProvider.Get]\tcannot read %s", path)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
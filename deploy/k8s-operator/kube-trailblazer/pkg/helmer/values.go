@@ -0,0 +1,205 @@
+package helmer
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+)
+
+// refPrefix marks a scalar value in ChartValues or ValuesYaml as a reference
+// to be resolved by a ValueProvider before the chart is installed, mirroring
+// helmfile/vals' "ref+<scheme>://..." convention, e.g.
+// "ref+vault://secret/data/myapp#password".
+const refPrefix = "ref+"
+
+// refRegex matches a "ref+<scheme>://..." reference token embedded anywhere
+// in a YAML values document, the same way runPinImages matches `image:`
+// fields to rewrite them in place.
+var refRegex = regexp.MustCompile(`ref\+[a-zA-Z][a-zA-Z0-9]*://[^\s"',}\]]+`)
+
+// ValueProvider resolves a single reference URI (with its "ref+" prefix
+// already stripped, leaving "<scheme>://...") to its secret material.
+type ValueProvider interface {
+	Get(uri string) (string, error)
+}
+
+var (
+	valueProvidersMu sync.RWMutex
+	valueProviders   = map[string]ValueProvider{}
+)
+
+// RegisterValueProvider makes provider available for references of the form
+// "ref+<scheme>://...". Providers that need no per-pipeline context (Vault,
+// AWS Secrets Manager) register themselves from init(); providers backed by
+// this operator's own clients (the in-cluster Kubernetes Secret provider)
+// are (re-)registered per InstallOrUpgradePackage call instead.
+func RegisterValueProvider(scheme string, provider ValueProvider) {
+	valueProvidersMu.Lock()
+	defer valueProvidersMu.Unlock()
+	valueProviders[scheme] = provider
+}
+
+// resolveValueRefs returns a copy of values with every "ref+<scheme>://..."
+// string leaf replaced by the secret material fetched from the scheme's
+// registered ValueProvider. The input is left untouched: HelmPackage's
+// shallow DeepCopyInto means ChartValues can be shared with a cached or
+// still-in-use copy of the owning CR, so resolving in place would leak
+// resolved secrets back into it.
+func resolveValueRefs(values chartutil.Values) (chartutil.Values, error) {
+	resolved, err := resolveValueNode(map[string]interface{}(values))
+	if err != nil {
+		return nil, err
+	}
+	return chartutil.Values(resolved.(map[string]interface{})), nil
+}
+
+// resolveValueNode walks node depth-first, returning a copy with every
+// string leaf that carries a refPrefix replaced by its resolved value.
+func resolveValueNode(node interface{}) (interface{}, error) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			resolved, err := resolveValueNode(val)
+			if err != nil {
+				return nil, errors.Wrapf(err, "[resolveValueNode]\tcannot resolve %s", key)
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			resolved, err := resolveValueNode(val)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case string:
+		if !strings.HasPrefix(v, refPrefix) {
+			return v, nil
+		}
+		return resolveRef(v)
+	default:
+		return v, nil
+	}
+}
+
+// resolveValuesYaml replaces every "ref+<scheme>://..." reference embedded
+// in valuesYaml with its resolved secret material, leaving the rest of the
+// document untouched.
+func resolveValuesYaml(valuesYaml string) (string, error) {
+	return resolveRefsInYAML(valuesYaml, refRegex, resolveRef)
+}
+
+// resolveRefsInYAML parses doc, replaces every ref match within each string
+// leaf via resolve, and re-marshals the result. Resolving against the
+// parsed tree and letting the YAML marshaler re-encode each leaf, instead of
+// substituting the resolved text directly into the raw YAML source, is what
+// keeps a resolved value that contains a colon, quote, or newline (entirely
+// plausible for a password or a multi-line cert/key) from corrupting the
+// surrounding document or injecting extra keys into it.
+func resolveRefsInYAML(doc string, ref *regexp.Regexp, resolve func(string) (string, error)) (string, error) {
+	var parsed map[string]interface{}
+	if err := yaml.Unmarshal([]byte(doc), &parsed); err != nil {
+		return "", errors.Wrap(err, "[resolveRefsInYAML]\tcannot parse YAML")
+	}
+
+	resolved, err := resolveRefsInNode(parsed, ref, resolve)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := yaml.Marshal(resolved)
+	if err != nil {
+		return "", errors.Wrap(err, "[resolveRefsInYAML]\tcannot re-marshal YAML")
+	}
+	return string(out), nil
+}
+
+// resolveRefsInNode walks node depth-first, returning a copy with every ref
+// match found within a string leaf replaced via resolve. Unlike
+// resolveValueNode, a leaf need not be entirely a reference: ref may match a
+// substring of a larger string value.
+func resolveRefsInNode(node interface{}, ref *regexp.Regexp, resolve func(string) (string, error)) (interface{}, error) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			resolved, err := resolveRefsInNode(val, ref, resolve)
+			if err != nil {
+				return nil, errors.Wrapf(err, "[resolveRefsInNode]\tcannot resolve %s", key)
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			resolved, err := resolveRefsInNode(val, ref, resolve)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case string:
+		if !ref.MatchString(v) {
+			return v, nil
+		}
+		var resolveErr error
+		out := ref.ReplaceAllStringFunc(v, func(match string) string {
+			if resolveErr != nil {
+				return match
+			}
+			value, err := resolve(match)
+			if err != nil {
+				resolveErr = err
+				return match
+			}
+			return value
+		})
+		if resolveErr != nil {
+			return "", resolveErr
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// resolveRef resolves a single "ref+<scheme>://..." string via the scheme's
+// registered ValueProvider.
+func resolveRef(ref string) (string, error) {
+	rest := strings.TrimPrefix(ref, refPrefix)
+	scheme, _, ok := strings.Cut(rest, "://")
+	if !ok {
+		return "", errors.Errorf("[resolveRef]\tmalformed reference %q, expected ref+<scheme>://...", ref)
+	}
+
+	valueProvidersMu.RLock()
+	provider, ok := valueProviders[scheme]
+	valueProvidersMu.RUnlock()
+	if !ok {
+		return "", errors.Errorf("[resolveRef]\tno ValueProvider registered for scheme %q", scheme)
+	}
+
+	value, err := provider.Get(rest)
+	if err != nil {
+		return "", errors.Wrapf(err, "[resolveRef]\tcannot resolve %q", ref)
+	}
+	return value, nil
+}
+
+// splitKeyFragment splits "<path>#<key>" into path and key, with key empty
+// when no "#" fragment is present.
+func splitKeyFragment(uri string) (path, key string) {
+	path, key, _ = strings.Cut(uri, "#")
+	return path, key
+}
@@ -0,0 +1,23 @@
+package helmer
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nvidia/kube-trailblazer/pkg/statuscheck"
+)
+
+// WaitReady polls every object rendered by the most recent
+// InstallOrUpgradePackage call until all of them report ready, backing off
+// exponentially between polls (see statuscheck.WaitForRelease), or until
+// timeout elapses, returning a single, structured readiness result covering
+// every object in the release. reconcileStage does not call it: WaitFor
+// predicates go through statuscheck.WaitForPredicate.
+func (h *Helmer) WaitReady(ctx context.Context, timeout, pollInterval time.Duration) (*statuscheck.Result, error) {
+	if h.LastRelease == nil {
+		return nil, errors.Errorf("[WaitReady]\tno release recorded for package %s, install it first", h.Package.ChartSpec.ReleaseName)
+	}
+	return statuscheck.WaitForRelease(ctx, h.KubeClient, h.LastRelease, timeout, pollInterval)
+}
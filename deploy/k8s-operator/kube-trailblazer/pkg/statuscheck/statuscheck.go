@@ -0,0 +1,425 @@
+// Package statuscheck waits for the resources produced by a Helm release to
+// become Ready, inspired by Helm's own kube.ReadyChecker
+// (helm.sh/helm/v3/pkg/kube).
+package statuscheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const pollInterval = 2 * time.Second
+
+// maxPollInterval caps WaitForRelease's exponential backoff between polls.
+const maxPollInterval = 30 * time.Second
+
+// defaultMaxUnavailable is the percentage Kubernetes itself defaults a
+// Deployment's RollingUpdate.MaxUnavailable to when the field is unset.
+var defaultMaxUnavailable = intstr.FromString("25%")
+
+// Reader is the subset of sigs.k8s.io/controller-runtime/pkg/client.Client
+// (also satisfied by pkg/clients.ClientsInterface) that readiness checks
+// need, so Helmer.WaitReady can call into this package with its own
+// KubeClient instead of requiring a controller-runtime client.Client.
+type Reader interface {
+	Get(ctx context.Context, key client.ObjectKey, obj client.Object) error
+}
+
+// ObjectStatus reports one rendered object's readiness.
+type ObjectStatus struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Ready     bool
+	// Message explains why Ready is false. Empty when Ready is true.
+	Message string
+}
+
+// Result is WaitForRelease's structured outcome, aggregated across every
+// object the release rendered instead of failing fast on the first one not
+// ready, so a caller can report (or act on) the whole picture.
+type Result struct {
+	// Ready is true once every object in the release reports ready.
+	Ready bool
+	// Objects is every object checked, in manifest order.
+	Objects []ObjectStatus
+}
+
+// NotReady returns the subset of Objects that were not ready.
+func (r *Result) NotReady() []ObjectStatus {
+	var notReady []ObjectStatus
+	for _, obj := range r.Objects {
+		if !obj.Ready {
+			notReady = append(notReady, obj)
+		}
+	}
+	return notReady
+}
+
+// manifestHead carries just enough of a rendered manifest to dispatch to a
+// per-kind readiness check.
+type manifestHead struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+// WaitForReleases polls the API server until every object rendered by every
+// release is Ready, backing off exponentially between polls per release
+// (see WaitForRelease) and honoring ctx.Done, instead of the fixed-interval
+// polling this used to do directly. timeout bounds the whole batch: it is
+// shared across releases via a single deadline, so a release later in the
+// slice gets whatever time is left over from the ones before it.
+func WaitForReleases(ctx context.Context, c Reader, releases []*release.Release, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for _, rel := range releases {
+		remaining := time.Until(deadline)
+		if remaining < 0 {
+			remaining = 0
+		}
+		result, err := WaitForRelease(ctx, c, rel, remaining, pollInterval)
+		if err != nil {
+			return errors.Wrapf(err, "[WaitForReleases]\trelease %s", rel.Name)
+		}
+		if !result.Ready {
+			return fmt.Errorf("[WaitForReleases]\trelease %s not ready within timeout: %d of %d objects not ready", rel.Name, len(result.NotReady()), len(result.Objects))
+		}
+	}
+	return nil
+}
+
+// WaitForPredicate blocks until every object in rel's manifest matching
+// predicate is Ready, or returns an error once deadline elapses. Recognized
+// forms are "CRD established", "Deployment ready" (matching every CRD/
+// Deployment the release renders), and "Job <name> Succeeded" (to target one
+// Job among several in the release).
+func WaitForPredicate(ctx context.Context, c Reader, rel *release.Release, predicate string, deadline time.Time) error {
+	kind, name, err := parsePredicate(predicate)
+	if err != nil {
+		return err
+	}
+
+	var matched bool
+	for _, manifest := range releaseutil.SplitManifests(rel.Manifest) {
+		var head manifestHead
+		if err := yaml.Unmarshal([]byte(manifest), &head); err != nil {
+			return errors.Wrap(err, "[WaitForPredicate]\tcannot parse rendered manifest")
+		}
+		if head.Kind != kind || (name != "" && head.Metadata.Name != name) {
+			continue
+		}
+		matched = true
+
+		key := types.NamespacedName{Namespace: head.Metadata.Namespace, Name: head.Metadata.Name}
+		if key.Namespace == "" {
+			key.Namespace = rel.Namespace
+		}
+
+		for {
+			ready, err := isReady(ctx, c, head.TypeMeta, key)
+			if err != nil {
+				return err
+			}
+			if ready {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("[WaitForPredicate]\ttimed out waiting for %q on release %s", predicate, rel.Name)
+			}
+			klog.Infof("[WaitForPredicate]\twaiting for %q (%s %s)", predicate, head.Kind, key)
+			time.Sleep(pollInterval)
+		}
+	}
+
+	if !matched {
+		return fmt.Errorf("[WaitForPredicate]\trelease %s has no %s matching %q", rel.Name, kind, predicate)
+	}
+	return nil
+}
+
+// parsePredicate resolves a WaitFor string into the kind (and, for a Job,
+// the name) it targets.
+func parsePredicate(predicate string) (kind string, name string, err error) {
+	fields := strings.Fields(predicate)
+	switch {
+	case len(fields) == 2 && fields[0] == "CRD" && fields[1] == "established":
+		return "CustomResourceDefinition", "", nil
+	case len(fields) == 2 && fields[0] == "Deployment" && fields[1] == "ready":
+		return "Deployment", "", nil
+	case len(fields) == 3 && fields[0] == "Job" && fields[2] == "Succeeded":
+		return "Job", fields[1], nil
+	default:
+		return "", "", fmt.Errorf("[parsePredicate]\tunrecognized WaitFor predicate %q", predicate)
+	}
+}
+
+// WaitForRelease polls every object rel renders until all of them report
+// ready, backing off exponentially between polls (starting at pollInterval,
+// doubling up to maxPollInterval) instead of hammering the API server at a
+// fixed rate. It never fails fast: every object is
+// checked on every pass, and the returned Result reports all of them, so a
+// caller can see the whole picture once timeout elapses rather than just the
+// first object that wasn't ready.
+func WaitForRelease(ctx context.Context, c Reader, rel *release.Release, timeout, pollInterval time.Duration) (*Result, error) {
+	deadline := time.Now().Add(timeout)
+	interval := pollInterval
+
+	for {
+		result, err := checkRelease(ctx, c, rel)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[WaitForRelease]\trelease %s", rel.Name)
+		}
+		if result.Ready || time.Now().After(deadline) {
+			return result, nil
+		}
+
+		klog.Infof("[WaitForRelease]\twaiting for release %s: %d of %d objects not ready", rel.Name, len(result.NotReady()), len(result.Objects))
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > maxPollInterval {
+			interval = maxPollInterval
+		}
+	}
+}
+
+// checkRelease is one readiness pass over every object rel renders.
+func checkRelease(ctx context.Context, c Reader, rel *release.Release) (*Result, error) {
+	result := &Result{Ready: true}
+
+	for _, manifest := range releaseutil.SplitManifests(rel.Manifest) {
+		var head manifestHead
+		if err := yaml.Unmarshal([]byte(manifest), &head); err != nil {
+			return nil, errors.Wrap(err, "[checkRelease]\tcannot parse rendered manifest")
+		}
+		if head.Kind == "" {
+			continue
+		}
+
+		key := types.NamespacedName{Namespace: head.Metadata.Namespace, Name: head.Metadata.Name}
+		if key.Namespace == "" {
+			key.Namespace = rel.Namespace
+		}
+
+		ready, message, err := checkReady(ctx, c, head.TypeMeta, key)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			result.Ready = false
+		}
+		result.Objects = append(result.Objects, ObjectStatus{
+			Kind:      head.Kind,
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Ready:     ready,
+			Message:   message,
+		})
+	}
+
+	return result, nil
+}
+
+func isReady(ctx context.Context, c Reader, typeMeta metav1.TypeMeta, key types.NamespacedName) (bool, error) {
+	ready, _, err := checkReady(ctx, c, typeMeta, key)
+	return ready, err
+}
+
+// checkReady is isReady's full form, additionally reporting why an object is
+// not ready so WaitForRelease can surface it in a Result instead of just a
+// deadline-exceeded error.
+func checkReady(ctx context.Context, c Reader, typeMeta metav1.TypeMeta, key types.NamespacedName) (bool, string, error) {
+	switch typeMeta.Kind {
+	case "Deployment":
+		obj := &appsv1.Deployment{}
+		if found, err := get(ctx, c, key, obj); !found || err != nil {
+			return false, "not found", err
+		}
+		maxUnavailable, err := deploymentMaxUnavailable(obj)
+		if err != nil {
+			return false, "", err
+		}
+		replicas := int32(1)
+		if obj.Spec.Replicas != nil {
+			replicas = *obj.Spec.Replicas
+		}
+		if obj.Status.ObservedGeneration < obj.Generation {
+			return false, "waiting for observed generation to catch up", nil
+		}
+		if obj.Status.UpdatedReplicas != replicas {
+			return false, fmt.Sprintf("%d of %d replicas updated", obj.Status.UpdatedReplicas, replicas), nil
+		}
+		if obj.Status.AvailableReplicas < replicas-maxUnavailable {
+			return false, fmt.Sprintf("%d of %d replicas available", obj.Status.AvailableReplicas, replicas), nil
+		}
+		return true, "", nil
+
+	case "StatefulSet":
+		obj := &appsv1.StatefulSet{}
+		if found, err := get(ctx, c, key, obj); !found || err != nil {
+			return false, "not found", err
+		}
+		replicas := int32(1)
+		if obj.Spec.Replicas != nil {
+			replicas = *obj.Spec.Replicas
+		}
+		if obj.Status.ReadyReplicas != replicas {
+			return false, fmt.Sprintf("%d of %d replicas ready", obj.Status.ReadyReplicas, replicas), nil
+		}
+		if obj.Status.UpdateRevision != "" && obj.Status.CurrentRevision != obj.Status.UpdateRevision {
+			return false, "waiting for the updated revision to roll out", nil
+		}
+		return true, "", nil
+
+	case "DaemonSet":
+		obj := &appsv1.DaemonSet{}
+		if found, err := get(ctx, c, key, obj); !found || err != nil {
+			return false, "not found", err
+		}
+		if obj.Status.NumberReady != obj.Status.DesiredNumberScheduled {
+			return false, fmt.Sprintf("%d of %d pods ready", obj.Status.NumberReady, obj.Status.DesiredNumberScheduled), nil
+		}
+		if obj.Status.UpdatedNumberScheduled != obj.Status.DesiredNumberScheduled {
+			return false, fmt.Sprintf("%d of %d pods updated", obj.Status.UpdatedNumberScheduled, obj.Status.DesiredNumberScheduled), nil
+		}
+		return true, "", nil
+
+	case "Pod":
+		obj := &v1.Pod{}
+		if found, err := get(ctx, c, key, obj); !found || err != nil {
+			return false, "not found", err
+		}
+		if obj.Status.Phase != v1.PodRunning {
+			return false, fmt.Sprintf("pod is %s", obj.Status.Phase), nil
+		}
+		for _, status := range obj.Status.ContainerStatuses {
+			if !status.Ready {
+				return false, fmt.Sprintf("container %s is not ready", status.Name), nil
+			}
+		}
+		return true, "", nil
+
+	case "Service":
+		svc := &v1.Service{}
+		if found, err := get(ctx, c, key, svc); !found || err != nil {
+			return false, "not found", err
+		}
+		if svc.Spec.ClusterIP == v1.ClusterIPNone {
+			return true, "", nil
+		}
+		if svc.Spec.ClusterIP == "" {
+			return false, "waiting for a ClusterIP to be assigned", nil
+		}
+		return true, "", nil
+
+	case "PersistentVolumeClaim":
+		obj := &v1.PersistentVolumeClaim{}
+		if found, err := get(ctx, c, key, obj); !found || err != nil {
+			return false, "not found", err
+		}
+		if obj.Status.Phase != v1.ClaimBound {
+			return false, fmt.Sprintf("claim is %s", obj.Status.Phase), nil
+		}
+		return true, "", nil
+
+	case "Job":
+		obj := &batchv1.Job{}
+		if found, err := get(ctx, c, key, obj); !found || err != nil {
+			return false, "not found", err
+		}
+		if obj.Status.Succeeded > 0 && obj.Status.Active == 0 {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("%d succeeded, %d active", obj.Status.Succeeded, obj.Status.Active), nil
+
+	case "CustomResourceDefinition":
+		obj := &apiextensionsv1.CustomResourceDefinition{}
+		if found, err := get(ctx, c, key, obj); !found || err != nil {
+			return false, "not found", err
+		}
+		var established bool
+		for _, cond := range obj.Status.Conditions {
+			switch cond.Type {
+			case apiextensionsv1.Established:
+				established = cond.Status == apiextensionsv1.ConditionTrue
+			case apiextensionsv1.NamesAccepted:
+				if cond.Status == apiextensionsv1.ConditionFalse {
+					return false, "names not accepted", nil
+				}
+			}
+		}
+		if !established {
+			return false, "waiting to be established", nil
+		}
+		return true, "", nil
+
+	default:
+		// Kinds we have no specific rule for are treated as ready as soon as
+		// they exist, matching Helm's own ReadyChecker default.
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(typeMeta.APIVersion, typeMeta.Kind))
+		found, err := get(ctx, c, key, obj)
+		if !found {
+			return false, "not found", err
+		}
+		return found, "", err
+	}
+}
+
+// deploymentMaxUnavailable resolves a Deployment's RollingUpdate.MaxUnavailable
+// (falling back to defaultMaxUnavailable, matching the apps/v1 API default)
+// against its current replica count.
+func deploymentMaxUnavailable(obj *appsv1.Deployment) (int32, error) {
+	replicas := int32(1)
+	if obj.Spec.Replicas != nil {
+		replicas = *obj.Spec.Replicas
+	}
+	maxUnavailable := defaultMaxUnavailable
+	if obj.Spec.Strategy.RollingUpdate != nil && obj.Spec.Strategy.RollingUpdate.MaxUnavailable != nil {
+		maxUnavailable = *obj.Spec.Strategy.RollingUpdate.MaxUnavailable
+	}
+	value, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailable, int(replicas), true)
+	if err != nil {
+		return 0, errors.Wrap(err, "[deploymentMaxUnavailable]\tcannot resolve MaxUnavailable")
+	}
+	return int32(value), nil
+}
+
+// get returns found=false (with a nil error) when the object does not exist,
+// so callers can treat "not yet created" as "not ready" instead of an error.
+func get(ctx context.Context, c Reader, key types.NamespacedName, obj client.Object) (bool, error) {
+	err := c.Get(ctx, key, obj)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,257 @@
+package statuscheck_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/nvidia/kube-trailblazer/pkg/statuscheck"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStatuscheck(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Statuscheck Suite")
+}
+
+const deploymentManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-deploy
+  namespace: test-ns
+`
+
+const jobManifest = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: my-job
+  namespace: test-ns
+`
+
+func job(succeeded bool) *batchv1.Job {
+	status := batchv1.JobStatus{}
+	if succeeded {
+		status.Succeeded = 1
+	} else {
+		status.Active = 1
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: "test-ns"},
+		Status:     status,
+	}
+}
+
+func statefulSet(ready bool) *appsv1.StatefulSet {
+	replicas := int32(1)
+	status := appsv1.StatefulSetStatus{ReadyReplicas: 1, CurrentRevision: "rev-1", UpdateRevision: "rev-1"}
+	if !ready {
+		status = appsv1.StatefulSetStatus{ReadyReplicas: 0, CurrentRevision: "rev-1", UpdateRevision: "rev-2"}
+	}
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-statefulset", Namespace: "test-ns"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     status,
+	}
+}
+
+func daemonSet(ready bool) *appsv1.DaemonSet {
+	status := appsv1.DaemonSetStatus{DesiredNumberScheduled: 1, NumberReady: 1, UpdatedNumberScheduled: 1}
+	if !ready {
+		status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 1, NumberReady: 0, UpdatedNumberScheduled: 0}
+	}
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-daemonset", Namespace: "test-ns"},
+		Status:     status,
+	}
+}
+
+func pod(ready bool) *v1.Pod {
+	p := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "test-ns"},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{{Name: "app", Ready: true}},
+		},
+	}
+	if !ready {
+		p.Status.ContainerStatuses[0].Ready = false
+	}
+	return p
+}
+
+func pvc(bound bool) *v1.PersistentVolumeClaim {
+	phase := v1.ClaimBound
+	if !bound {
+		phase = v1.ClaimPending
+	}
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pvc", Namespace: "test-ns"},
+		Status:     v1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
+
+func service(assigned bool) *v1.Service {
+	clusterIP := ""
+	if assigned {
+		clusterIP = "10.0.0.1"
+	}
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-svc", Namespace: "test-ns"},
+		Spec:       v1.ServiceSpec{ClusterIP: clusterIP},
+	}
+}
+
+func crd(established bool) *apiextensionsv1.CustomResourceDefinition {
+	conditions := []apiextensionsv1.CustomResourceDefinitionCondition{
+		{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionTrue},
+	}
+	if established {
+		conditions = append(conditions, apiextensionsv1.CustomResourceDefinitionCondition{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue})
+	}
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-crd"},
+		Status:     apiextensionsv1.CustomResourceDefinitionStatus{Conditions: conditions},
+	}
+}
+
+func deployment(ready bool) *appsv1.Deployment {
+	replicas := int32(1)
+	status := appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	if !ready {
+		status = appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 0, AvailableReplicas: 0}
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-deploy", Namespace: "test-ns", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     status,
+	}
+}
+
+var _ = Describe("WaitForReleases", func() {
+	It("returns immediately when the Deployment is already Ready", func() {
+		c := fake.NewClientBuilder().WithRuntimeObjects(runtime.Object(deployment(true))).Build()
+		rel := &release.Release{Name: "my-release", Namespace: "test-ns", Manifest: deploymentManifest}
+
+		err := statuscheck.WaitForReleases(context.TODO(), c, []*release.Release{rel}, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("times out when the Deployment never becomes Ready", func() {
+		c := fake.NewClientBuilder().WithRuntimeObjects(runtime.Object(deployment(false))).Build()
+		rel := &release.Release{Name: "my-release", Namespace: "test-ns", Manifest: deploymentManifest}
+
+		err := statuscheck.WaitForReleases(context.TODO(), c, []*release.Release{rel}, 10*time.Millisecond)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns once ctx is cancelled instead of polling past it", func() {
+		c := fake.NewClientBuilder().WithRuntimeObjects(runtime.Object(deployment(false))).Build()
+		rel := &release.Release{Name: "my-release", Namespace: "test-ns", Manifest: deploymentManifest}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := statuscheck.WaitForReleases(ctx, c, []*release.Release{rel}, time.Minute)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WaitForPredicate", func() {
+	It("resolves 'Job <name> Succeeded' against the matching Job in the release", func() {
+		c := fake.NewClientBuilder().WithRuntimeObjects(runtime.Object(job(true))).Build()
+		rel := &release.Release{Name: "my-release", Namespace: "test-ns", Manifest: jobManifest}
+
+		err := statuscheck.WaitForPredicate(context.TODO(), c, rel, "Job my-job Succeeded", time.Now().Add(time.Second))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("times out when the named Job never succeeds", func() {
+		c := fake.NewClientBuilder().WithRuntimeObjects(runtime.Object(job(false))).Build()
+		rel := &release.Release{Name: "my-release", Namespace: "test-ns", Manifest: jobManifest}
+
+		err := statuscheck.WaitForPredicate(context.TODO(), c, rel, "Job my-job Succeeded", time.Now())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects an unrecognized predicate", func() {
+		c := fake.NewClientBuilder().Build()
+		rel := &release.Release{Name: "my-release", Namespace: "test-ns", Manifest: jobManifest}
+
+		err := statuscheck.WaitForPredicate(context.TODO(), c, rel, "Pod running", time.Now().Add(time.Second))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("waits for every Deployment matching 'Deployment ready', not just the first", func() {
+		second := deployment(false)
+		second.Name = "my-deploy-2"
+		secondManifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-deploy-2
+  namespace: test-ns
+`
+		c := fake.NewClientBuilder().WithRuntimeObjects(runtime.Object(deployment(true)), runtime.Object(second)).Build()
+		rel := &release.Release{Name: "my-release", Namespace: "test-ns", Manifest: deploymentManifest + "---\n" + secondManifest}
+
+		err := statuscheck.WaitForPredicate(context.TODO(), c, rel, "Deployment ready", time.Now().Add(10*time.Millisecond))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WaitForRelease", func() {
+	manifestFor := func(kind, name string) string {
+		return fmt.Sprintf("apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n  namespace: test-ns\n", kind, name)
+	}
+
+	DescribeTable("reports Ready per resource kind",
+		func(manifest string, obj runtime.Object, releaseNamespace string, wantReady bool) {
+			// The CustomResourceDefinition entries need apiextensions/v1
+			// registered, which the fake client's default scheme lacks.
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(obj).Build()
+			rel := &release.Release{Name: "my-release", Namespace: releaseNamespace, Manifest: manifest}
+
+			result, err := statuscheck.WaitForRelease(context.TODO(), c, rel, 10*time.Millisecond, time.Millisecond)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Ready).To(Equal(wantReady))
+		},
+		Entry("StatefulSet ready", "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: my-statefulset\n  namespace: test-ns\n", statefulSet(true), "test-ns", true),
+		Entry("StatefulSet not ready", "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: my-statefulset\n  namespace: test-ns\n", statefulSet(false), "test-ns", false),
+		Entry("DaemonSet ready", "apiVersion: apps/v1\nkind: DaemonSet\nmetadata:\n  name: my-daemonset\n  namespace: test-ns\n", daemonSet(true), "test-ns", true),
+		Entry("DaemonSet not ready", "apiVersion: apps/v1\nkind: DaemonSet\nmetadata:\n  name: my-daemonset\n  namespace: test-ns\n", daemonSet(false), "test-ns", false),
+		Entry("Pod ready", manifestFor("Pod", "my-pod"), pod(true), "test-ns", true),
+		Entry("Pod not ready", manifestFor("Pod", "my-pod"), pod(false), "test-ns", false),
+		Entry("PersistentVolumeClaim bound", manifestFor("PersistentVolumeClaim", "my-pvc"), pvc(true), "test-ns", true),
+		Entry("PersistentVolumeClaim pending", manifestFor("PersistentVolumeClaim", "my-pvc"), pvc(false), "test-ns", false),
+		Entry("Service with a ClusterIP", manifestFor("Service", "my-svc"), service(true), "test-ns", true),
+		Entry("Service without a ClusterIP", manifestFor("Service", "my-svc"), service(false), "test-ns", false),
+		Entry("CustomResourceDefinition established", "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: my-crd\n", crd(true), "", true),
+		Entry("CustomResourceDefinition not yet established", "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: my-crd\n", crd(false), "", false),
+	)
+
+	It("aggregates every object instead of failing on the first one not ready", func() {
+		c := fake.NewClientBuilder().WithRuntimeObjects(deployment(true), job(false)).Build()
+		rel := &release.Release{Name: "my-release", Namespace: "test-ns", Manifest: deploymentManifest + "---\n" + jobManifest}
+
+		result, err := statuscheck.WaitForRelease(context.TODO(), c, rel, 10*time.Millisecond, time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Ready).To(BeFalse())
+		Expect(result.Objects).To(HaveLen(2))
+		Expect(result.NotReady()).To(HaveLen(1))
+		Expect(result.NotReady()[0].Kind).To(Equal("Job"))
+	})
+})
@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DriverKind selects which Kubernetes object type backs a release record.
+type DriverKind string
+
+const (
+	// ConfigMapDriver stores release records as ConfigMaps, mirroring Helm's
+	// storage/driver/cfgmaps.go.
+	ConfigMapDriver DriverKind = "configmap"
+	// SecretDriver stores release records as Secrets, mirroring Helm's
+	// storage/driver/secrets.go. Preferred when manifests/values may carry
+	// sensitive data.
+	SecretDriver DriverKind = "secret"
+)
+
+// releaseDriver reads and writes individual Release revisions as
+// label-selectable Kubernetes objects, one object per revision.
+type releaseDriver interface {
+	name() string
+	get(ctx context.Context, namespace, key string) (*Release, error)
+	list(ctx context.Context, namespace string, matchingLabels map[string]string) ([]*Release, error)
+	create(ctx context.Context, namespace, key string, rel *Release) error
+	update(ctx context.Context, namespace, key string, rel *Release) error
+	delete(ctx context.Context, namespace, key string) error
+}
+
+// releaseKey returns the per-revision object name, following Helm's
+// "<prefix>.<name>.v<version>" convention.
+func releaseKey(prefix, name string, version int) string {
+	return fmt.Sprintf("%s.%s.v%d", prefix, name, version)
+}
+
+// configMapDriver backs releaseDriver with ConfigMap objects.
+type configMapDriver struct {
+	kubeClient clients.ClientsInterface
+}
+
+func (d *configMapDriver) name() string { return string(ConfigMapDriver) }
+
+func (d *configMapDriver) get(ctx context.Context, namespace, key string) (*Release, error) {
+	cm := &v1.ConfigMap{}
+	if err := d.kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: key}, cm); err != nil {
+		return nil, err
+	}
+	return releaseFromData(cm.Data, cm.Labels)
+}
+
+func (d *configMapDriver) list(ctx context.Context, namespace string, matchingLabels map[string]string) ([]*Release, error) {
+	list := &v1.ConfigMapList{}
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabels(matchingLabels)}
+	if err := d.kubeClient.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+
+	releases := make([]*Release, 0, len(list.Items))
+	for _, cm := range list.Items {
+		rel, err := releaseFromData(cm.Data, cm.Labels)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, rel)
+	}
+	return releases, nil
+}
+
+func (d *configMapDriver) create(ctx context.Context, namespace, key string, rel *Release) error {
+	data, err := releaseToData(rel)
+	if err != nil {
+		return err
+	}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: key, Namespace: namespace, Labels: releaseLabels(rel)},
+		Data:       data,
+	}
+	return d.kubeClient.Create(ctx, cm)
+}
+
+func (d *configMapDriver) update(ctx context.Context, namespace, key string, rel *Release) error {
+	cm := &v1.ConfigMap{}
+	if err := d.kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: key}, cm); err != nil {
+		return err
+	}
+	data, err := releaseToData(rel)
+	if err != nil {
+		return err
+	}
+	cm.Data = data
+	cm.Labels = releaseLabels(rel)
+	return d.kubeClient.Update(ctx, cm)
+}
+
+func (d *configMapDriver) delete(ctx context.Context, namespace, key string) error {
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key, Namespace: namespace}}
+	return d.kubeClient.Delete(ctx, cm)
+}
+
+// secretDriver backs releaseDriver with Secret objects.
+type secretDriver struct {
+	kubeClient clients.ClientsInterface
+}
+
+func (d *secretDriver) name() string { return string(SecretDriver) }
+
+func (d *secretDriver) get(ctx context.Context, namespace, key string) (*Release, error) {
+	s := &v1.Secret{}
+	if err := d.kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: key}, s); err != nil {
+		return nil, err
+	}
+	return releaseFromData(secretToStringData(s.Data), s.Labels)
+}
+
+func (d *secretDriver) list(ctx context.Context, namespace string, matchingLabels map[string]string) ([]*Release, error) {
+	list := &v1.SecretList{}
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabels(matchingLabels)}
+	if err := d.kubeClient.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+
+	releases := make([]*Release, 0, len(list.Items))
+	for _, s := range list.Items {
+		rel, err := releaseFromData(secretToStringData(s.Data), s.Labels)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, rel)
+	}
+	return releases, nil
+}
+
+func (d *secretDriver) create(ctx context.Context, namespace, key string, rel *Release) error {
+	data, err := releaseToData(rel)
+	if err != nil {
+		return err
+	}
+	s := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: key, Namespace: namespace, Labels: releaseLabels(rel)},
+		StringData: data,
+		Type:       v1.SecretTypeOpaque,
+	}
+	return d.kubeClient.Create(ctx, s)
+}
+
+func (d *secretDriver) update(ctx context.Context, namespace, key string, rel *Release) error {
+	s := &v1.Secret{}
+	if err := d.kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: key}, s); err != nil {
+		return err
+	}
+	data, err := releaseToData(rel)
+	if err != nil {
+		return err
+	}
+	s.StringData = data
+	s.Labels = releaseLabels(rel)
+	return d.kubeClient.Update(ctx, s)
+}
+
+func (d *secretDriver) delete(ctx context.Context, namespace, key string) error {
+	s := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: key, Namespace: namespace}}
+	return d.kubeClient.Delete(ctx, s)
+}
+
+func secretToStringData(data map[string][]byte) map[string]string {
+	out := make(map[string]string, len(data))
+	for k, v := range data {
+		out[k] = string(v)
+	}
+	return out
+}
+
+// releaseToData gzip+base64-encodes the manifest and values before they are
+// written to the backing object, keeping entries under the 1MiB object limit.
+func releaseToData(rel *Release) (map[string]string, error) {
+	manifest, err := encodePayload(rel.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	values, err := encodePayload(rel.Values)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"name":     rel.Name,
+		"version":  strconv.Itoa(rel.Version),
+		"owner":    rel.Owner,
+		"status":   rel.Status,
+		"manifest": manifest,
+		"values":   values,
+	}, nil
+}
+
+func releaseFromData(data map[string]string, labels map[string]string) (*Release, error) {
+	manifest, err := decodePayload(data["manifest"])
+	if err != nil {
+		return nil, err
+	}
+	values, err := decodePayload(data["values"])
+	if err != nil {
+		return nil, err
+	}
+	version, _ := strconv.Atoi(data["version"])
+
+	rel := &Release{
+		Name:     data["name"],
+		Version:  version,
+		Owner:    data["owner"],
+		Status:   data["status"],
+		Manifest: manifest,
+		Values:   values,
+	}
+	if rel.Name == "" {
+		rel.Name = labels[labelName]
+	}
+	if rel.Status == "" {
+		rel.Status = labels[labelStatus]
+	}
+	return rel, nil
+}
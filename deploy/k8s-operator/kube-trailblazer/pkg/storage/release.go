@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Release is a single revision of a deployed HelmPackage, modeled on Helm's
+// own release record (see helm.sh/helm/v3/pkg/storage/driver).
+type Release struct {
+	Name     string `json:"name"`
+	Version  int    `json:"version"`
+	Owner    string `json:"owner"`
+	Status   string `json:"status"`
+	Manifest string `json:"manifest"`
+	Values   string `json:"values"`
+}
+
+// labelName/labelOwner/labelStatus/labelVersion are the Kubernetes labels
+// attached to every backing ConfigMap/Secret so releases can be queried by
+// selector, mirroring Helm's storage/driver/cfgmaps.go and secrets.go.
+const (
+	labelName    = "name"
+	labelOwner   = "owner"
+	labelStatus  = "status"
+	labelVersion = "version"
+
+	defaultOwner = "trailblazer"
+)
+
+// releaseLabels returns the selector labels for a release, attached to the
+// backing object when it is written so Query can later select on them.
+func releaseLabels(r *Release) map[string]string {
+	owner := r.Owner
+	if owner == "" {
+		owner = defaultOwner
+	}
+	return map[string]string{
+		labelName:    r.Name,
+		labelOwner:   owner,
+		labelStatus:  r.Status,
+		labelVersion: strconv.Itoa(r.Version),
+	}
+}
+
+// encodePayload gzip+base64-encodes data so a release record (manifest +
+// values) stays well under the 1MiB Kubernetes object limit.
+func encodePayload(data string) (string, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		return "", errors.Wrap(err, "[encodePayload]\tcannot gzip payload")
+	}
+	if err := w.Close(); err != nil {
+		return "", errors.Wrap(err, "[encodePayload]\tcannot close gzip writer")
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decodePayload reverses encodePayload.
+func decodePayload(data string) (string, error) {
+	if data == "" {
+		return "", nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", errors.Wrap(err, "[decodePayload]\tcannot base64-decode payload")
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", errors.Wrap(err, "[decodePayload]\tcannot create gzip reader")
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return "", errors.Wrap(err, "[decodePayload]\tcannot gunzip payload")
+	}
+
+	return string(out), nil
+}
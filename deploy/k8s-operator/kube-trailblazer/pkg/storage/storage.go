@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
 	v1 "k8s.io/api/core/v1"
@@ -13,7 +14,23 @@ import (
 
 //go:generate mockgen -source=storage.go -package=storage -destination=mock_storage_api.go
 
+// Storage is a release-history backend modeled on Helm's own
+// ConfigMaps/Secrets storage drivers (helm.sh/helm/v3/pkg/storage). Every
+// revision of a release is kept as an individually labeled object so it can
+// be queried, listed and rolled back to, independent of the live Helm
+// release secret.
+//
+// CheckConfigMapEntry/UpdateConfigMapEntry/DeleteConfigMapEntry are kept as a
+// thin compatibility shim over a single-key ConfigMap for callers that only
+// need scalar bookkeeping and do not care about revision history.
 type Storage interface {
+	Create(ctx context.Context, namespace string, rel *Release) error
+	Update(ctx context.Context, namespace string, rel *Release) error
+	Delete(ctx context.Context, namespace, name string, version int) error
+	Query(ctx context.Context, namespace string, matchingLabels map[string]string) ([]*Release, error)
+	History(ctx context.Context, namespace, name string) ([]*Release, error)
+	Last(ctx context.Context, namespace, name string) (*Release, error)
+
 	CheckConfigMapEntry(context.Context, string, types.NamespacedName) (string, error)
 	UpdateConfigMapEntry(context.Context, string, string, types.NamespacedName) error
 	DeleteConfigMapEntry(context.Context, string, types.NamespacedName) error
@@ -21,10 +38,87 @@ type Storage interface {
 
 type storage struct {
 	kubeClient clients.ClientsInterface
+	driver     releaseDriver
+}
+
+// NewStorage creates a Storage backed by the requested driver. ConfigMapDriver
+// is used for any kind other than SecretDriver, including an empty one.
+func NewStorage(kubeClient clients.ClientsInterface, kind DriverKind) Storage {
+	var driver releaseDriver
+	switch kind {
+	case SecretDriver:
+		driver = &secretDriver{kubeClient: kubeClient}
+	default:
+		driver = &configMapDriver{kubeClient: kubeClient}
+	}
+	return &storage{kubeClient: kubeClient, driver: driver}
+}
+
+func (s *storage) Create(ctx context.Context, namespace string, rel *Release) error {
+	key := releaseKey(s.driver.name(), rel.Name, rel.Version)
+	if err := s.driver.create(ctx, namespace, key, rel); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to create release record", "namespace", namespace, "name", rel.Name, "version", rel.Version)
+		return err
+	}
+	return nil
+}
+
+func (s *storage) Update(ctx context.Context, namespace string, rel *Release) error {
+	key := releaseKey(s.driver.name(), rel.Name, rel.Version)
+	if err := s.driver.update(ctx, namespace, key, rel); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to update release record", "namespace", namespace, "name", rel.Name, "version", rel.Version)
+		return err
+	}
+	return nil
+}
+
+func (s *storage) Delete(ctx context.Context, namespace, name string, version int) error {
+	key := releaseKey(s.driver.name(), name, version)
+	if err := s.driver.delete(ctx, namespace, key); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to delete release record", "namespace", namespace, "name", name, "version", version)
+		return err
+	}
+	return nil
+}
+
+// Query returns every release record matching the given label selector,
+// e.g. {"name": "my-release", "status": "deployed"}.
+func (s *storage) Query(ctx context.Context, namespace string, matchingLabels map[string]string) ([]*Release, error) {
+	releases, err := s.driver.list(ctx, namespace, matchingLabels)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to query release records", "namespace", namespace, "labels", matchingLabels)
+		return nil, err
+	}
+	return releases, nil
+}
+
+// History returns every revision of name ordered by version, oldest first.
+func (s *storage) History(ctx context.Context, namespace, name string) ([]*Release, error) {
+	releases, err := s.Query(ctx, namespace, map[string]string{labelName: name})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(releases); i++ {
+		for j := i + 1; j < len(releases); j++ {
+			if releases[j].Version < releases[i].Version {
+				releases[i], releases[j] = releases[j], releases[i]
+			}
+		}
+	}
+	return releases, nil
 }
 
-func NewStorage(kubeClient clients.ClientsInterface) Storage {
-	return &storage{kubeClient: kubeClient}
+// Last returns the most recent revision of name, or an error if none exist.
+func (s *storage) Last(ctx context.Context, namespace, name string) (*Release, error) {
+	history, err := s.History(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("[Last]\tno release history found for %s/%s", namespace, name)
+	}
+	return history[len(history)-1], nil
 }
 
 func (s *storage) CheckConfigMapEntry(ctx context.Context, key string, ins types.NamespacedName) (string, error) {
@@ -12,6 +12,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -51,7 +52,7 @@ var _ = Describe("storage_CheckConfigMapEntry", func() {
 			Get(context.TODO(), nsn, &v1.ConfigMap{}).
 			Return(notFound)
 
-		_, err := storage.NewStorage(mockClient).CheckConfigMapEntry(context.TODO(), key, nsn)
+		_, err := storage.NewStorage(mockClient, storage.ConfigMapDriver).CheckConfigMapEntry(context.TODO(), key, nsn)
 		Expect(err).To(HaveOccurred())
 	})
 
@@ -60,7 +61,7 @@ var _ = Describe("storage_CheckConfigMapEntry", func() {
 			EXPECT().
 			Get(context.TODO(), nsn, &v1.ConfigMap{})
 
-		_, err := storage.NewStorage(mockClient).CheckConfigMapEntry(context.TODO(), key, nsn)
+		_, err := storage.NewStorage(mockClient, storage.ConfigMapDriver).CheckConfigMapEntry(context.TODO(), key, nsn)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -74,7 +75,7 @@ var _ = Describe("storage_CheckConfigMapEntry", func() {
 				cm.Data = map[string]string{key: data}
 			})
 
-		v, err := storage.NewStorage(mockClient).CheckConfigMapEntry(context.TODO(), key, nsn)
+		v, err := storage.NewStorage(mockClient, storage.ConfigMapDriver).CheckConfigMapEntry(context.TODO(), key, nsn)
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(v).To(Equal(data))
@@ -88,7 +89,7 @@ var _ = Describe("UpdateConfigMapEntry", func() {
 			Get(context.TODO(), nsn, &v1.ConfigMap{}).
 			Return(notFound)
 
-		err := storage.NewStorage(mockClient).UpdateConfigMapEntry(context.TODO(), "any-key", "any-value", nsn)
+		err := storage.NewStorage(mockClient, storage.ConfigMapDriver).UpdateConfigMapEntry(context.TODO(), "any-key", "any-value", nsn)
 		Expect(err).To(HaveOccurred())
 	})
 
@@ -107,7 +108,7 @@ var _ = Describe("UpdateConfigMapEntry", func() {
 				}),
 		)
 
-		err := storage.NewStorage(mockClient).UpdateConfigMapEntry(context.TODO(), key, value, nsn)
+		err := storage.NewStorage(mockClient, storage.ConfigMapDriver).UpdateConfigMapEntry(context.TODO(), key, value, nsn)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -130,7 +131,7 @@ var _ = Describe("UpdateConfigMapEntry", func() {
 				}),
 		)
 
-		err := storage.NewStorage(mockClient).UpdateConfigMapEntry(context.TODO(), key, newValue, nsn)
+		err := storage.NewStorage(mockClient, storage.ConfigMapDriver).UpdateConfigMapEntry(context.TODO(), key, newValue, nsn)
 		Expect(err).NotTo(HaveOccurred())
 	})
 })
@@ -142,7 +143,7 @@ var _ = Describe("DeleteConfigMapEntry", func() {
 			Get(context.TODO(), nsn, &v1.ConfigMap{}).
 			Return(notFound)
 
-		err := storage.NewStorage(mockClient).DeleteConfigMapEntry(context.TODO(), "any-key", nsn)
+		err := storage.NewStorage(mockClient, storage.ConfigMapDriver).DeleteConfigMapEntry(context.TODO(), "any-key", nsn)
 		Expect(err).To(HaveOccurred())
 	})
 
@@ -151,7 +152,7 @@ var _ = Describe("DeleteConfigMapEntry", func() {
 			EXPECT().
 			Get(context.TODO(), nsn, &v1.ConfigMap{})
 
-		err := storage.NewStorage(mockClient).DeleteConfigMapEntry(context.TODO(), "some-other-key", nsn)
+		err := storage.NewStorage(mockClient, storage.ConfigMapDriver).DeleteConfigMapEntry(context.TODO(), "some-other-key", nsn)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -178,7 +179,39 @@ var _ = Describe("DeleteConfigMapEntry", func() {
 				}),
 		)
 
-		err := storage.NewStorage(mockClient).DeleteConfigMapEntry(context.TODO(), otherKey, nsn)
+		err := storage.NewStorage(mockClient, storage.ConfigMapDriver).DeleteConfigMapEntry(context.TODO(), otherKey, nsn)
 		Expect(err).NotTo(HaveOccurred())
 	})
 })
+
+var _ = Describe("storage_History", func() {
+	It("should order revisions oldest to newest regardless of list order", func() {
+		newer := v1.ConfigMap{Data: map[string]string{"name": "my-release", "version": "2"}}
+		older := v1.ConfigMap{Data: map[string]string{"name": "my-release", "version": "1"}}
+
+		mockClient.
+			EXPECT().
+			List(context.TODO(), gomock.AssignableToTypeOf(&v1.ConfigMapList{}), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, list *v1.ConfigMapList, _ ...client.ListOption) {
+				list.Items = []v1.ConfigMap{newer, older}
+			})
+
+		history, err := storage.NewStorage(mockClient, storage.ConfigMapDriver).History(context.TODO(), namespaceName, "my-release")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(history).To(HaveLen(2))
+		Expect(history[0].Version).To(Equal(1))
+		Expect(history[1].Version).To(Equal(2))
+	})
+})
+
+var _ = Describe("storage_Last", func() {
+	It("should return an error when no history exists", func() {
+		mockClient.
+			EXPECT().
+			List(context.TODO(), gomock.AssignableToTypeOf(&v1.ConfigMapList{}), gomock.Any(), gomock.Any())
+
+		_, err := storage.NewStorage(mockClient, storage.ConfigMapDriver).Last(context.TODO(), namespaceName, "my-release")
+		Expect(err).To(HaveOccurred())
+	})
+})